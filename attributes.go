@@ -3,3 +3,16 @@ package jsonapi
 // Attributes is a JSON:API document resource attributes object.
 // See https://jsonapi.org/format/#document-resource-object-attributes.
 type Attributes map[string]interface{}
+
+// AttributeMarshaler is the interface implemented by types that can marshal themselves into a
+// JSON:API attribute or meta value. The returned value is encoded with encoding/json.
+type AttributeMarshaler interface {
+	MarshalAttribute() (interface{}, error)
+}
+
+// AttributeUnmarshaler is the interface implemented by types that can unmarshal a JSON:API
+// attribute or meta value of themselves. v holds the value as decoded by encoding/json into an
+// interface{}.
+type AttributeUnmarshaler interface {
+	UnmarshalAttribute(v interface{}) error
+}
@@ -0,0 +1,250 @@
+package jsonapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// An Encoder writes JSON:API documents to an output stream. Primary data resources are marshaled
+// and written one at a time, so neither they nor the whole document encoding are held in memory.
+// Included resources are kept until the primary data is written, since they follow it.
+type Encoder struct {
+	s      *Serializer
+	w      io.Writer
+	format Format
+	prefix string
+	indent string
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return defaultSerializer.NewEncoder(w)
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func (s *Serializer) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		s:      s,
+		w:      w,
+		format: s.format,
+		prefix: s.jsonPrefix,
+		indent: s.jsonIndent,
+	}
+}
+
+// SetFormat sets the format of the documents written by the encoder, which defaults to the one of
+// its Serializer. DefaultFormat restores it.
+func (e *Encoder) SetFormat(format Format) {
+	if format == DefaultFormat {
+		format = e.s.format
+	}
+	e.format = format
+}
+
+// SetIndent makes the encoder pretty-print documents with prefix and indent, which default to the
+// ones of its Serializer. See https://golang.org/pkg/encoding/json/#Encoder.SetIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.format = PrettyFormat
+	e.prefix = prefix
+	e.indent = indent
+}
+
+// Encode writes the JSON:API encoding of v to the stream. Primary data resources are marshaled and
+// written one at a time, followed by the included resources, so the encoding of the whole document
+// is never held in memory. Nothing is written when marshaling fails on the first resource, but
+// failing on a later one leaves a truncated document in the stream. Unlike json.Encoder, no
+// newline is written after the document.
+func (e *Encoder) Encode(v interface{}, p *MarshalParams) error {
+	return e.encode(v, p, e.formatOf(p), func() {})
+}
+
+// EncodeErrors writes the JSON:API errors encoding of errs to the stream.
+func (e *Encoder) EncodeErrors(p *MarshalParams, errs ...Error) error {
+	document := newErrorsDocument(p, errs)
+	var body []byte
+	var err error
+	if e.formatOf(p) == PrettyFormat {
+		body, err = json.MarshalIndent(document, e.prefix, e.indent)
+	} else {
+		body, err = json.Marshal(document)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(body)
+	return err
+}
+
+// formatOf returns the format of a document encoded with p.
+func (e *Encoder) formatOf(p *MarshalParams) Format {
+	if p != nil && p.Format != DefaultFormat {
+		return p.Format
+	}
+	return e.format
+}
+
+// encode writes the JSON:API encoding of v in format, calling start right before writing anything.
+func (e *Encoder) encode(v interface{}, p *MarshalParams, format Format, start func()) error {
+	data, err := e.s.newPrimaryData(v, p)
+	if err != nil {
+		return err
+	}
+	// marshal the first resource before writing anything, so its errors leave the stream untouched
+	var first *Resource
+	if len(data.values) > 0 {
+		if first, err = data.marshal(0); err != nil {
+			return err
+		}
+	}
+	start()
+
+	dw := &documentWriter{
+		w: bufio.NewWriter(e.w),
+	}
+	if format == PrettyFormat {
+		dw.pretty = true
+		dw.prefix = e.prefix
+		dw.indent = e.indent
+	}
+	dw.writeString("{" + dw.newline(1) + `"data":` + dw.space())
+	switch {
+	case !data.isSlice:
+		dw.writeValue(first, 1)
+	case len(data.values) == 0:
+		dw.writeString("[]")
+	default:
+		dw.writeString("[")
+		for i := range data.values {
+			resource := first
+			if i > 0 {
+				if resource, err = data.marshal(i); err != nil {
+					return err
+				}
+				dw.writeString(",")
+			}
+			dw.writeString(dw.newline(2))
+			dw.writeValue(resource, 2)
+			if dw.err != nil {
+				return dw.err
+			}
+		}
+		dw.writeString(dw.newline(1) + "]")
+	}
+	dw.writeMembers(data.document)
+	if dw.err != nil {
+		return dw.err
+	}
+	return dw.w.Flush()
+}
+
+// documentWriter writes the pieces of a top-level document as json.Marshal, or json.MarshalIndent
+// when pretty, would. The first error is kept and makes later writes no-ops.
+type documentWriter struct {
+	w      *bufio.Writer
+	pretty bool
+	prefix string
+	indent string
+	err    error
+}
+
+// newline returns the start of a line at nesting depth when pretty.
+func (dw *documentWriter) newline(depth int) string {
+	if !dw.pretty {
+		return ""
+	}
+	return "\n" + dw.prefix + strings.Repeat(dw.indent, depth)
+}
+
+// space returns the separator between member names and values.
+func (dw *documentWriter) space() string {
+	if !dw.pretty {
+		return ""
+	}
+	return " "
+}
+
+// writeValue writes the encoding of v at nesting depth.
+func (dw *documentWriter) writeValue(v interface{}, depth int) {
+	if data := dw.encode(v, depth); data != nil {
+		_, dw.err = dw.w.Write(data)
+	}
+}
+
+// writeMembers writes the members of d following data, closing the top-level object. Document and
+// CompoundDocument hold their data member before the ones of d.
+func (dw *documentWriter) writeMembers(d *document) {
+	data := dw.encode(d, 0)
+	if data == nil {
+		return
+	}
+	if len(data) == len("{}") {
+		dw.writeString(dw.newline(0) + "}")
+		return
+	}
+	// the opening brace of d is replaced by the separator from data
+	dw.writeString(",")
+	if dw.err == nil {
+		_, dw.err = dw.w.Write(data[1:])
+	}
+}
+
+// encode returns the encoding of v at nesting depth, or nil if it fails.
+func (dw *documentWriter) encode(v interface{}, depth int) []byte {
+	if dw.err != nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		dw.err = err
+		return nil
+	}
+	if !dw.pretty {
+		return data
+	}
+	var buf bytes.Buffer
+	if dw.err = json.Indent(&buf, data, dw.prefix+strings.Repeat(dw.indent, depth), dw.indent); dw.err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func (dw *documentWriter) writeString(str string) {
+	if dw.err != nil {
+		return
+	}
+	_, dw.err = dw.w.WriteString(str)
+}
+
+// A Decoder reads JSON:API documents from an input stream.
+type Decoder struct {
+	s   *Serializer
+	dec *json.Decoder
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return defaultSerializer.NewDecoder(r)
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func (s *Serializer) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		s:   s,
+		dec: json.NewDecoder(r),
+	}
+}
+
+// Decode reads the next JSON:API document from its input and stores it in the value pointed to by
+// v.
+func (d *Decoder) Decode(v interface{}) error {
+	return d.DecodeWithParams(v, nil)
+}
+
+// DecodeWithParams reads the next JSON:API document from its input and stores it in the value
+// pointed to by v, as configured by p.
+func (d *Decoder) DecodeWithParams(v interface{}, p *UnmarshalParams) error {
+	return d.s.decodeDocument(v, p, d.dec.Decode)
+}
@@ -0,0 +1,156 @@
+package jsonapi
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestEncoder(t *testing.T) {
+	type Person struct {
+		ID   string `jsonapi:"primary,people"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	type Article struct {
+		ID     string  `jsonapi:"primary,articles"`
+		Title  string  `jsonapi:"attribute,title"`
+		Author *Person `jsonapi:"relationship,author"`
+	}
+	author := &Person{ID: "1", Name: "Jane"}
+	articles := []*Article{
+		{ID: "1", Title: "First", Author: author},
+		{ID: "2", Title: "Second", Author: author},
+	}
+	self := "http://example.com/articles"
+	params := &MarshalParams{
+		Links: &Links{"self": self},
+		Meta:  &Meta{"total": 2},
+	}
+
+	type encoderTest struct {
+		v      interface{}
+		params *MarshalParams
+	}
+	tests := map[string]encoderTest{
+		"document":                {v: articles[0]},
+		"compound document":       {v: &articles, params: params},
+		"empty compound document": {v: &[]*Article{}},
+		"profile":                 {v: author, params: &MarshalParams{Profile: []string{"https://example.com/profile"}}},
+		"pretty document":         {v: articles[0], params: &MarshalParams{Format: PrettyFormat}},
+		"pretty compound document": {v: &articles, params: &MarshalParams{
+			Links:  params.Links,
+			Meta:   params.Meta,
+			Format: PrettyFormat,
+		}},
+		"pretty empty compound document": {v: &[]*Article{}, params: &MarshalParams{Format: PrettyFormat}},
+	}
+	for name, test := range tests {
+		expected, err := Marshal(test.v, test.params)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(test.v, test.params); err != nil {
+			t.Errorf("%s: expected no error, got: %s", name, err.Error())
+			continue
+		}
+		if bytes.Compare(buf.Bytes(), expected) != 0 {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", name, string(expected), buf.String())
+		}
+	}
+
+	// test errors documents
+	errs := []Error{{Status: "404", Title: "Not Found"}, {Status: "409", Title: "Conflict"}}
+	var buf bytes.Buffer
+	for _, errorsParams := range []*MarshalParams{params, {Format: PrettyFormat}} {
+		expected, _ := MarshalErrors(errorsParams, errs...)
+		buf.Reset()
+		if err := NewEncoder(&buf).EncodeErrors(errorsParams, errs...); err != nil {
+			t.Fatal(err.Error())
+		}
+		if bytes.Compare(buf.Bytes(), expected) != 0 {
+			t.Errorf("expected:\n%s\ngot:\n%s", string(expected), buf.String())
+		}
+	}
+
+	// test custom indent
+	buf.Reset()
+	encoder := NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(author, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []byte(`{
+  "data": {
+    "id": "1",
+    "type": "people",
+    "attributes": {
+      "name": "Jane"
+    }
+  },
+  "jsonapi": {
+    "version": "1.0"
+  }
+}`)
+	if bytes.Compare(buf.Bytes(), expected) != 0 {
+		t.Errorf("expected:\n%s\ngot:\n%s", string(expected), buf.String())
+	}
+
+	// test DefaultFormat restores the format of the serializer
+	buf.Reset()
+	encoder.SetFormat(DefaultFormat)
+	if err := encoder.Encode(author, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected = []byte(`{"data":{"id":"1","type":"people","attributes":{"name":"Jane"}},"jsonapi":{"version":"1.0"}}`)
+	if bytes.Compare(buf.Bytes(), expected) != 0 {
+		t.Errorf("expected:\n%s\ngot:\n%s", string(expected), buf.String())
+	}
+
+	// test marshal and write errors
+	if err := NewEncoder(&buf).Encode(*author, nil); err == nil || err.Error() != "v must be pointer or slice" {
+		t.Errorf("expected error: v must be pointer or slice, got: %v", err)
+	}
+	if err := NewEncoder(failingWriter{}).Encode(author, nil); err == nil || err.Error() != "write failed" {
+		t.Errorf("expected error: write failed, got: %v", err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	type Person struct {
+		ID   string `jsonapi:"primary,people"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	stream := `{"data": {"id": "1", "type": "people", "attributes": {"name": "Jane"}}}
+{"data": [{"id": "2", "type": "people", "attributes": {"name": "John"}}]}
+{"data": {"id": "3", "type": "people", "attributes": {"age": 30}}}`
+	decoder := NewDecoder(strings.NewReader(stream))
+
+	person := Person{}
+	if err := decoder.Decode(&person); err != nil {
+		t.Fatal(err.Error())
+	}
+	if person != (Person{ID: "1", Name: "Jane"}) {
+		t.Errorf("expected: %+v, got: %+v", Person{ID: "1", Name: "Jane"}, person)
+	}
+
+	people := []*Person{}
+	if err := decoder.Decode(&people); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(people) != 1 || *people[0] != (Person{ID: "2", Name: "John"}) {
+		t.Errorf("expected: [%+v], got: %+v", Person{ID: "2", Name: "John"}, people)
+	}
+
+	expectedError := "type: people, pointer: /data/attributes/age, attribute: age, not found"
+	if err := decoder.DecodeWithParams(&person, &UnmarshalParams{Strict: true}); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+}
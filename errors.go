@@ -1,6 +1,15 @@
 package jsonapi
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
 
 // Error is a JSON:API error object.
 // See https://jsonapi.org/format/#error-objects.
@@ -18,3 +27,182 @@ type Error struct {
 func (e *Error) Error() string {
 	return fmt.Sprintf("%+v", *e)
 }
+
+// typeError is an error caused by the Go type a document is unmarshaled into rather than by the
+// document itself, e.g.: a field of an unsupported type.
+type typeError struct {
+	msg string
+}
+
+func newTypeError(format string, a ...interface{}) error {
+	return &typeError{msg: fmt.Sprintf(format, a...)}
+}
+
+func (e *typeError) Error() string {
+	return e.msg
+}
+
+// newMemberError annotates err with the resource type and member names it occurred on.
+func newMemberError(resourceType string, memberNames []string, err error) error {
+	return fmt.Errorf("type: %s, member: %s, %w", resourceType, strings.Join(memberNames, "."), err)
+}
+
+// UnmarshalError describes a member of a JSON:API document that couldn't be stored in a Go value.
+// Unmarshal returns member errors as *UnmarshalError.
+type UnmarshalError struct {
+	// ResourceType is the type of the resource holding the member, e.g.: people, empty when the
+	// resource has no type.
+	ResourceType string
+	// Pointer is the JSON pointer to the member in the document, e.g.: /data/attributes/age.
+	Pointer string
+	// Type is the Go type Value couldn't be stored in, nil when the member itself is invalid, e.g.:
+	// an unknown attribute in strict mode.
+	Type reflect.Type
+	// Value is the received member value.
+	Value interface{}
+	Err   error
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.ResourceType == "" {
+		return fmt.Sprintf("pointer: %s, %s", e.Pointer, e.Err)
+	}
+	return fmt.Sprintf("type: %s, pointer: %s, %s", e.ResourceType, e.Pointer, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalErrors holds every member error found by Unmarshal when collecting all errors.
+type UnmarshalErrors []*UnmarshalError
+
+func (e UnmarshalErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// status returns the HTTP status code of a request whose body has the error: 500 Internal Server
+// Error when caused by the Go type, 422 Unprocessable Entity for values of the wrong type, 400 Bad
+// Request otherwise.
+func (e *UnmarshalError) status() int {
+	var typeErr *typeError
+	if errors.As(e.Err, &typeErr) {
+		return http.StatusInternalServerError
+	}
+	if e.Type != nil {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// errorObject returns the JSON:API error object describing e, pointing to the member in source.
+func (e *UnmarshalError) errorObject() Error {
+	status := e.status()
+	if status == http.StatusInternalServerError {
+		return newInternalServerError()
+	}
+	detail := e.Err.Error()
+	if e.Type != nil {
+		detail = fmt.Sprintf("%s, expected %s, got %s", detail, e.Type, describeValue(e.Value))
+	}
+	return Error{
+		Status: strconv.Itoa(status),
+		Title:  http.StatusText(status),
+		Detail: detail,
+		Source: map[string]string{
+			"pointer": e.Pointer,
+		},
+	}
+}
+
+// NewErrors returns the JSON:API error objects describing err, ready to be passed to
+// RespondError or MarshalErrors. *Error values are returned as is, *UnmarshalError values and
+// each error in UnmarshalErrors point to the invalid member in source, malformed JSON is described
+// as a 400 Bad Request and any other error, e.g.: v not being a pointer, as a 500 Internal Server
+// Error without details.
+func NewErrors(err error) []Error {
+	var jsonapiErr *Error
+	if errors.As(err, &jsonapiErr) {
+		return []Error{*jsonapiErr}
+	}
+	var unmarshalErrs UnmarshalErrors
+	if errors.As(err, &unmarshalErrs) {
+		errs := make([]Error, len(unmarshalErrs))
+		for i, unmarshalErr := range unmarshalErrs {
+			errs[i] = unmarshalErr.errorObject()
+		}
+		return errs
+	}
+	var unmarshalErr *UnmarshalError
+	if errors.As(err, &unmarshalErr) {
+		return []Error{unmarshalErr.errorObject()}
+	}
+	var syntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &jsonTypeErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return []Error{{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Title:  http.StatusText(http.StatusBadRequest),
+			Detail: err.Error(),
+		}}
+	}
+	return []Error{newInternalServerError()}
+}
+
+// newInternalServerError returns the error object of errors that aren't caused by the request,
+// whose details aren't meant for clients.
+func newInternalServerError() Error {
+	return Error{
+		Status: strconv.Itoa(http.StatusInternalServerError),
+		Title:  http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+// ErrorStatus returns the HTTP status code of a response with errs: the status they share, or the
+// most generally applicable one when they differ, 400 Bad Request for client errors and 500
+// Internal Server Error otherwise.
+// See https://jsonapi.org/format/#errors-processing.
+func ErrorStatus(errs ...Error) int {
+	status := 0
+	for _, err := range errs {
+		errStatus, convErr := strconv.Atoi(err.Status)
+		if convErr != nil || errStatus < 400 || errStatus > 599 {
+			return http.StatusInternalServerError
+		}
+		switch {
+		case status == 0 || status == errStatus:
+			status = errStatus
+		case status >= 500 || errStatus >= 500:
+			status = http.StatusInternalServerError
+		default:
+			status = http.StatusBadRequest
+		}
+	}
+	if status == 0 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// describeValue returns the JSON type of decoded JSON value v, and v itself for scalars.
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -1,6 +1,8 @@
 package jsonapi
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -15,3 +17,94 @@ func TestError(t *testing.T) {
 		t.Errorf("expected error:\n%s\ngot error:\n%s\n", expected, errorString)
 	}
 }
+
+func TestNewErrors(t *testing.T) {
+	type TestNewErrors struct {
+		ID      string   `jsonapi:"primary,test_new_errors"`
+		Title   string   `jsonapi:"attribute,title"`
+		Channel chan int `jsonapi:"attribute,channel"`
+	}
+	tests := map[string]struct {
+		input    string
+		params   *UnmarshalParams
+		expected []Error
+	}{
+		"wrong type": {
+			input: `{"data": {"id": "1", "type": "test_new_errors", "attributes": {"title": 12}}}`,
+			expected: []Error{{
+				Status: "422",
+				Title:  "Unprocessable Entity",
+				Detail: "invalid value for field string, expected string, got number 12",
+				Source: map[string]string{"pointer": "/data/attributes/title"},
+			}},
+		},
+		"unknown attribute": {
+			input:  `{"data": {"id": "1", "type": "test_new_errors", "attributes": {"name": "x"}}}`,
+			params: &UnmarshalParams{Strict: true},
+			expected: []Error{{
+				Status: "400",
+				Title:  "Bad Request",
+				Detail: "attribute: name, not found",
+				Source: map[string]string{"pointer": "/data/attributes/name"},
+			}},
+		},
+		"unsupported type": {
+			input:  `{"data": {"id": "1", "type": "test_new_errors", "attributes": {"channel": 1}}}`,
+			params: &UnmarshalParams{Strict: true},
+			expected: []Error{{
+				Status: "500",
+				Title:  "Internal Server Error",
+			}},
+		},
+		"malformed document": {
+			input: `{"data": `,
+			expected: []Error{{
+				Status: "400",
+				Title:  "Bad Request",
+				Detail: "unexpected end of JSON input",
+			}},
+		},
+	}
+	for name, test := range tests {
+		err := UnmarshalWithParams([]byte(test.input), &TestNewErrors{}, test.params)
+		if err == nil {
+			t.Errorf("%s: expected error, got no error", name)
+			continue
+		}
+		if got := NewErrors(err); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected errors: %+v, got: %+v", name, test.expected, got)
+		}
+	}
+
+	// test errors not caused by the document
+	err := Unmarshal([]byte(`{"data": {"id": "1", "type": "test_new_errors"}}`), TestNewErrors{})
+	if got := NewErrors(err); !reflect.DeepEqual(got, []Error{{Status: "500", Title: "Internal Server Error"}}) {
+		t.Errorf("expected internal server error, got: %+v", got)
+	}
+
+	// test errors objects are returned as is
+	includeErr := &Error{Status: "400", Title: "Invalid include parameter"}
+	if got := NewErrors(fmt.Errorf("marshal: %w", includeErr)); !reflect.DeepEqual(got, []Error{*includeErr}) {
+		t.Errorf("expected errors: %+v, got: %+v", []Error{*includeErr}, got)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		errs     []Error
+		expected int
+	}{
+		{errs: []Error{{Status: "409"}}, expected: 409},
+		{errs: []Error{{Status: "422"}, {Status: "422"}}, expected: 422},
+		{errs: []Error{{Status: "422"}, {Status: "400"}}, expected: 400},
+		{errs: []Error{{Status: "403"}, {Status: "422"}, {Status: "400"}}, expected: 400},
+		{errs: []Error{{Status: "422"}, {Status: "503"}}, expected: 500},
+		{errs: []Error{{Status: "422"}, {}}, expected: 500},
+		{expected: 500},
+	}
+	for _, test := range tests {
+		if got := ErrorStatus(test.errs...); got != test.expected {
+			t.Errorf("%+v: expected status: %d, got: %d", test.errs, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package jsonapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ParseFields returns the sparse fieldsets requested in the fields[TYPE] query parameters of r,
+// keyed by resource type. It returns nil when no fieldset was requested.
+// See https://jsonapi.org/format/#fetching-sparse-fieldsets.
+func ParseFields(r *http.Request) map[string][]string {
+	var fields map[string][]string
+	for key, values := range r.URL.Query() {
+		if !strings.HasPrefix(key, "fields[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		resourceType := strings.TrimSuffix(strings.TrimPrefix(key, "fields["), "]")
+		names := []string{}
+		for _, value := range values {
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+		if fields == nil {
+			fields = make(map[string][]string)
+		}
+		fields[resourceType] = names
+	}
+	return fields
+}
+
+// fieldsets holds the attribute and relationship member names to marshal per resource type.
+type fieldsets map[string]map[string]bool
+
+func newFieldsets(fields map[string][]string) fieldsets {
+	if fields == nil {
+		return nil
+	}
+	fs := make(fieldsets, len(fields))
+	for resourceType, names := range fields {
+		fs[resourceType] = make(map[string]bool, len(names))
+		for _, name := range names {
+			fs[resourceType][name] = true
+		}
+	}
+	return fs
+}
+
+// allows reports whether member name of resourceType should be marshaled. Resource types without
+// a fieldset marshal all of their members.
+func (fs fieldsets) allows(resourceType, name string) bool {
+	fieldset, ok := fs[resourceType]
+	return !ok || fieldset[name]
+}
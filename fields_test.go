@@ -0,0 +1,131 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := map[string]map[string][]string{
+		"http://example.com/articles":                   nil,
+		"http://example.com/articles?include=author":    nil,
+		"http://example.com/articles?fields[articles]=": {"articles": {}},
+		"http://example.com/articles?fields[articles]=title,body&fields[people]=name": {
+			"articles": {"title", "body"},
+			"people":   {"name"},
+		},
+	}
+	for url, expected := range tests {
+		got := ParseFields(httptest.NewRequest("GET", url, nil))
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected fields for %s: %#v, got: %#v", url, expected, got)
+		}
+	}
+}
+
+func TestMarshalFields(t *testing.T) {
+	type Author struct {
+		ID       string `jsonapi:"primary,people"`
+		Name     string `jsonapi:"attribute,name"`
+		Twitter  string `jsonapi:"attribute,twitter"`
+		Verified bool   `jsonapi:"meta,verified"`
+	}
+	type Article struct {
+		ID     string  `jsonapi:"primary,articles"`
+		Title  string  `jsonapi:"attribute,title"`
+		Body   string  `jsonapi:"attribute,body"`
+		Author *Author `jsonapi:"relationship,author"`
+		Editor *Author `jsonapi:"relationship,editor"`
+	}
+	articles := []*Article{
+		{
+			ID:    "article-1",
+			Title: "Hello world!",
+			Body:  "Lorem ipsum",
+			Author: &Author{
+				ID:       "author-1",
+				Name:     "John",
+				Twitter:  "@john",
+				Verified: true,
+			},
+			Editor: &Author{
+				ID:      "author-2",
+				Name:    "Juan",
+				Twitter: "@juan",
+			},
+		},
+	}
+	expected := []byte(`{
+	"data": [
+		{
+			"id": "article-1",
+			"type": "articles",
+			"attributes": {
+				"title": "Hello world!"
+			},
+			"relationships": {
+				"author": {
+					"data": {
+						"id": "author-1",
+						"type": "people"
+					}
+				}
+			}
+		}
+	],
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"included": [
+		{
+			"id": "author-1",
+			"type": "people",
+			"attributes": {
+				"name": "John"
+			},
+			"meta": {
+				"verified": true
+			}
+		}
+	]
+}`)
+	if got, err := Marshal(&articles, &MarshalParams{
+		Format: PrettyFormat,
+		Fields: map[string][]string{
+			"articles": {"title", "author"},
+			"people":   {"name"},
+		},
+	}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+
+	// explicitly included resources are side-loaded even if their relationship is not in the fieldset
+	got, err := Marshal(&articles, &MarshalParams{
+		Format:  PrettyFormat,
+		Include: []string{"editor"},
+		Fields: map[string][]string{
+			"articles": {},
+			"people":   {},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cd := CompoundDocument{}
+	if err := json.Unmarshal(got, &cd); err != nil {
+		t.Fatal(err)
+	}
+	if len(cd.Data) != 1 || len(cd.Data[0].Attributes) != 0 || len(cd.Data[0].Relationships) != 0 {
+		t.Errorf("expected article without attributes and relationships, got:\n%s\n", string(got))
+	}
+	if len(cd.Included) != 1 || cd.Included[0].ID != "author-2" || len(cd.Included[0].Attributes) != 0 {
+		t.Errorf("expected only the editor without attributes to be included, got:\n%s\n", string(got))
+	}
+}
@@ -0,0 +1,77 @@
+package jsonapi
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// formatID returns the resource object id encoding of idValue. IDs implementing
+// encoding.TextMarshaler are encoded as text, and strings and integers of any kind as is. Other
+// custom id types must implement encoding.TextMarshaler and encoding.TextUnmarshaler, so they
+// decode back with setID, addressable IDs may implement them with pointer receivers. Pointer IDs
+// are dereferenced, nil ones encode as an empty id.
+func formatID(idValue reflect.Value) (string, error) {
+	if idValue.Kind() == reflect.Ptr {
+		if idValue.IsNil() {
+			return "", nil
+		}
+		return formatID(idValue.Elem())
+	}
+	marshalerValue := idValue
+	if idValue.CanAddr() {
+		marshalerValue = idValue.Addr()
+	}
+	if m, ok := marshalerValue.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch idValue.Kind() {
+	case reflect.String:
+		return idValue.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(idValue.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(idValue.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("ID must be a string or int, got %s", idValue.Kind())
+}
+
+// setID stores resource object id in field, the reverse of formatID. Fields implementing
+// encoding.TextUnmarshaler are decoded from text, and integers are parsed within the size of
+// their kind.
+func setID(field reflect.Value, id string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setID(elem.Elem(), id); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(id))
+		}
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(id)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intID, err := strconv.ParseInt(id, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(intID)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		uintID, err := strconv.ParseUint(id, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(uintID)
+	default:
+		return newTypeError("ID must be a string or int")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package jsonapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type TestUserID string
+
+type TestVersion struct {
+	Major, Minor int
+}
+
+func (v TestVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// TestCode implements encoding.TextMarshaler and encoding.TextUnmarshaler with pointer receivers.
+type TestCode struct {
+	Prefix string
+	Number int
+}
+
+func (c *TestCode) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%s-%d", c.Prefix, c.Number)), nil
+}
+
+func (c *TestCode) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%1s-%d", &c.Prefix, &c.Number)
+	return err
+}
+
+func TestFormatAndSetID(t *testing.T) {
+	intID := 42
+	ids := map[string]interface{}{
+		"-9223372036854775808": int64(math.MinInt64),
+		"18446744073709551615": uint64(math.MaxUint64),
+		"255":                  uint8(math.MaxUint8),
+		"user-1":               TestUserID("user-1"),
+		"0a0b0c0d":             UUID{10, 11, 12, 13},
+		"42":                   &intID,
+	}
+	for expected, id := range ids {
+		got, err := formatID(reflect.ValueOf(id))
+		if err != nil {
+			t.Errorf("%T: %s", id, err.Error())
+			continue
+		}
+		if got != expected {
+			t.Errorf("%T: expected id: %s, got: %s", id, expected, got)
+		}
+
+		// set the id back in a new value of the same type
+		field := reflect.New(reflect.TypeOf(id)).Elem()
+		if err := setID(field, got); err != nil {
+			t.Errorf("%T: %s", id, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(field.Interface(), id) {
+			t.Errorf("%T: expected id: %v, got: %v", id, id, field.Interface())
+		}
+	}
+
+	// addressable ids may implement encoding.TextMarshaler with pointer receivers
+	code := reflect.New(reflect.TypeOf(TestCode{})).Elem()
+	code.Set(reflect.ValueOf(TestCode{"A", 7}))
+	if got, err := formatID(code); err != nil || got != "A-7" {
+		t.Errorf("expected id: %s, got: %s, %v", "A-7", got, err)
+	}
+
+	// custom id types must implement encoding.TextMarshaler, fmt.Stringer isn't enough
+	expectedStringerError := "ID must be a string or int, got struct"
+	if _, err := formatID(reflect.ValueOf(TestVersion{1, 2})); err == nil || err.Error() != expectedStringerError {
+		t.Errorf("expected error: %s, got: %v", expectedStringerError, err)
+	}
+	if err := setID(reflect.New(reflect.TypeOf(TestVersion{})).Elem(), "1.2"); err == nil {
+		t.Errorf("expected error setting a stringer id, got no error")
+	}
+
+	// nil pointers encode as an empty id
+	if got, err := formatID(reflect.ValueOf((*int)(nil))); err != nil || got != "" {
+		t.Errorf("expected empty id, got: %s, %v", got, err)
+	}
+
+	// ids must fit in their kind
+	expectedError := `strconv.ParseInt: parsing "128": value out of range`
+	if err := setID(reflect.New(reflect.TypeOf(int8(0))).Elem(), "128"); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+}
+
+func TestMarshalUnmarshalIDs(t *testing.T) {
+	type TestIDs struct {
+		ID     uint64      `jsonapi:"primary,test_ids"`
+		Author *TestIDUser `jsonapi:"relationship,author"`
+		Code   *TestIDCode `jsonapi:"relationship,code"`
+	}
+	test := TestIDs{
+		ID:     math.MaxUint64,
+		Author: &TestIDUser{ID: "user-1"},
+		Code:   &TestIDCode{ID: TestCode{"A", 7}},
+	}
+	b, err := Marshal(&test, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	got := TestIDs{}
+	if err := Unmarshal(b, &got); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(got, test) {
+		t.Errorf("expected: %+v, got: %+v", test, got)
+	}
+}
+
+type TestIDUser struct {
+	ID TestUserID `jsonapi:"primary,test_id_users"`
+}
+
+type TestIDCode struct {
+	ID TestCode `jsonapi:"primary,test_id_codes"`
+}
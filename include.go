@@ -0,0 +1,96 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParseInclude returns the relationship paths requested in the include query parameter of r.
+// It returns nil when the parameter isn't present.
+// See https://jsonapi.org/format/#fetching-includes.
+func ParseInclude(r *http.Request) []string {
+	values, ok := r.URL.Query()["include"]
+	if !ok {
+		return nil
+	}
+	paths := []string{}
+	for _, value := range values {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// includeTree is a tree of relationship member names built from dotted include paths. A nil tree
+// includes every related resource.
+type includeTree map[string]includeTree
+
+func (s *Serializer) newIncludeTree(t reflect.Type, paths []string) (includeTree, error) {
+	tree := includeTree{}
+	for _, path := range paths {
+		node := tree
+		nodeType := t
+		for _, name := range strings.Split(path, ".") {
+			relType, ok := s.findRelationship(nodeType, name)
+			if !ok {
+				return nil, &Error{
+					Status: strconv.Itoa(http.StatusBadRequest),
+					Title:  "Invalid include parameter",
+					Detail: fmt.Sprintf("relationship path '%s' does not exist", path),
+					Source: map[string]string{
+						"parameter": "include",
+					},
+				}
+			}
+			if _, ok := node[name]; !ok {
+				node[name] = includeTree{}
+			}
+			node = node[name]
+			nodeType = relType
+		}
+	}
+	return tree, nil
+}
+
+// findRelationship returns the resource type of the relationship member named name in struct type t.
+func (s *Serializer) findRelationship(t reflect.Type, name string) (reflect.Type, bool) {
+	t = resourceType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get(s.tagKey) == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if relType, ok := s.findRelationship(field.Type, name); ok {
+				return relType, true
+			}
+			continue
+		}
+		if _, ok := field.Tag.Lookup(s.tagKey); !ok {
+			continue
+		}
+		memberType, memberName, _, err := getMember(field, s.tagKey)
+		if err != nil || memberType != memberTypeRelationship || memberName != name {
+			continue
+		}
+		return resourceType(field.Type), true
+	}
+	return nil, false
+}
+
+// resourceType strips pointers and slices from t.
+func resourceType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
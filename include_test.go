@@ -0,0 +1,212 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseInclude(t *testing.T) {
+	tests := map[string][]string{
+		"http://example.com/articles":                                  nil,
+		"http://example.com/articles?include=":                         {},
+		"http://example.com/articles?include=author":                   {"author"},
+		"http://example.com/articles?include=author,comments.author":   {"author", "comments.author"},
+		"http://example.com/articles?include=author&include=comments,": {"author", "comments"},
+	}
+	for url, expected := range tests {
+		got := ParseInclude(httptest.NewRequest("GET", url, nil))
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected include paths for %s: %#v, got: %#v", url, expected, got)
+		}
+	}
+}
+
+func TestMarshalInclude(t *testing.T) {
+	type Author struct {
+		ID   string `jsonapi:"primary,authors"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	type Comment struct {
+		ID     string  `jsonapi:"primary,comments"`
+		Body   string  `jsonapi:"attribute,body"`
+		Author *Author `jsonapi:"relationship,author"`
+	}
+	type Article struct {
+		ID       string     `jsonapi:"primary,articles"`
+		Title    string     `jsonapi:"attribute,title"`
+		Author   *Author    `jsonapi:"relationship,author"`
+		Comments []*Comment `jsonapi:"relationship,comments"`
+	}
+	article := Article{
+		ID:    "article-1",
+		Title: "Hello world!",
+		Author: &Author{
+			ID:   "author-1",
+			Name: "John",
+		},
+		Comments: []*Comment{
+			{
+				ID:   "comment-1",
+				Body: "First!",
+				Author: &Author{
+					ID:   "author-2",
+					Name: "Juan",
+				},
+			},
+		},
+	}
+
+	// only side-load the requested paths
+	expectedComments := []byte(`{
+	"data": {
+		"id": "article-1",
+		"type": "articles",
+		"attributes": {
+			"title": "Hello world!"
+		},
+		"relationships": {
+			"author": {
+				"data": {
+					"id": "author-1",
+					"type": "authors"
+				}
+			},
+			"comments": {
+				"data": [
+					{
+						"id": "comment-1",
+						"type": "comments"
+					}
+				]
+			}
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"included": [
+		{
+			"id": "comment-1",
+			"type": "comments",
+			"attributes": {
+				"body": "First!"
+			},
+			"relationships": {
+				"author": {
+					"data": {
+						"id": "author-2",
+						"type": "authors"
+					}
+				}
+			}
+		}
+	]
+}`)
+	if got, err := Marshal(&article, &MarshalParams{Include: []string{"comments"}, Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expectedComments) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expectedComments), string(got))
+		}
+	}
+
+	// nested paths
+	if got, err := Marshal(&article, &MarshalParams{Include: []string{"author", "comments.author"}, Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		d := Document{}
+		if err := json.Unmarshal(got, &d); err != nil {
+			t.Fatal(err)
+		}
+		includedKeys := []resourceKey{}
+		for _, incl := range d.Included {
+			includedKeys = append(includedKeys, incl.key())
+		}
+		expectedKeys := []resourceKey{
+			{Type: "authors", ID: "author-1"},
+			{Type: "comments", ID: "comment-1"},
+			{Type: "authors", ID: "author-2"},
+		}
+		if !reflect.DeepEqual(includedKeys, expectedKeys) {
+			t.Errorf("expected included: %+v, got: %+v", expectedKeys, includedKeys)
+		}
+	}
+
+	// empty include paths only emit resource linkage
+	if got, err := Marshal(&article, &MarshalParams{Include: []string{}, Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Contains(got, []byte(`"included"`)) {
+			t.Errorf("expected no included resources, got:\n%s\n", string(got))
+		}
+	}
+
+	// unknown relationship paths
+	invalidPaths := []string{"editor", "author.comments", "comments.author.publisher", "title"}
+	for _, path := range invalidPaths {
+		_, err := Marshal(&article, &MarshalParams{Include: []string{path}})
+		e, ok := err.(*Error)
+		switch {
+		case !ok:
+			t.Errorf("expected include path: %s, to error out with *Error, got: %v", path, err)
+		case e.Status != "400" || e.Source["parameter"] != "include":
+			t.Errorf("expected include path: %s, to error out with a 400 include parameter error, got: %+v", path, *e)
+		}
+	}
+}
+
+func TestMarshalIncludeCompound(t *testing.T) {
+	type Publisher struct {
+		ID   string `jsonapi:"primary,publishers"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	type Author struct {
+		ID        string     `jsonapi:"primary,authors"`
+		Name      string     `jsonapi:"attribute,name"`
+		Publisher *Publisher `jsonapi:"relationship,publisher"`
+	}
+	type Book struct {
+		ID       string  `jsonapi:"primary,books"`
+		Author   *Author `jsonapi:"relationship,author"`
+		Reviewer *Author `jsonapi:"relationship,reviewer"`
+	}
+	author := &Author{
+		ID:   "author-1",
+		Name: "Carl",
+		Publisher: &Publisher{
+			ID:   "publisher-1",
+			Name: "Random House",
+		},
+	}
+	books := []*Book{
+		{
+			ID:       "book-1",
+			Author:   author,
+			Reviewer: author,
+		},
+	}
+
+	// the author is reached through a shorter path first, but its publisher must still be included
+	got, err := Marshal(&books, &MarshalParams{Include: []string{"author", "reviewer.publisher"}, Format: PrettyFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cd := CompoundDocument{}
+	if err := json.Unmarshal(got, &cd); err != nil {
+		t.Fatal(err)
+	}
+	includedKeys := []resourceKey{}
+	for _, incl := range cd.Included {
+		includedKeys = append(includedKeys, incl.key())
+	}
+	expectedKeys := []resourceKey{
+		{Type: "authors", ID: "author-1"},
+		{Type: "publishers", ID: "publisher-1"},
+	}
+	if !reflect.DeepEqual(includedKeys, expectedKeys) {
+		t.Errorf("expected included: %+v, got: %+v", expectedKeys, includedKeys)
+	}
+}
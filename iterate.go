@@ -5,9 +5,9 @@ import (
 	"reflect"
 )
 
-type iterFunc func(reflect.Value, memberType, ...string) error
+type iterFunc func(reflect.Value, memberType, tagOptions, ...string) error
 
-func iterateStruct(v interface{}, iter iterFunc, memberNames ...string) error {
+func (s *Serializer) iterateStruct(v interface{}, iter iterFunc, memberNames ...string) error {
 	rType := reflect.TypeOf(v)
 	rValue := reflect.ValueOf(v)
 
@@ -33,30 +33,46 @@ func iterateStruct(v interface{}, iter iterFunc, memberNames ...string) error {
 		fValue := rValue.Elem().Field(i)
 		kind := fValue.Kind()
 
+		// skip ignored fields
+		if fType.Tag.Get(s.tagKey) == "-" {
+			continue
+		}
+
 		// if struct and embedded (anonymus), restart loop
 		if kind == reflect.Struct && fType.Anonymous {
-			iterateStruct(fValue.Addr().Interface(), iter, memberNames...)
+			if err := s.iterateStruct(fValue.Addr().Interface(), iter, memberNames...); err != nil {
+				return err
+			}
 			continue
 		}
 
 		// if tag exists, get member info, continue otherwise
-		if _, ok := fType.Tag.Lookup(tagKey); !ok {
+		if _, ok := fType.Tag.Lookup(s.tagKey); !ok {
 			continue
 		}
-		memberType, memberName, err := getMember(fType)
+		memberType, memberName, options, err := getMember(fType, s.tagKey)
 		if err != nil {
 			return err
 		}
 
-		// handle nested structs
-		if kind == reflect.Struct {
-			iterateStruct(fValue.Addr().Interface(), iter, append(memberNames, memberName)...)
-			continue
-		}
-
-		if err := iter(fValue, memberType, append(memberNames, memberName)...); err != nil {
+		if err := iter(fValue, memberType, options, append(memberNames, memberName)...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// hasTaggedFields reports whether struct type t, or any struct embedded in it, has a field tagged
+// with the tag key.
+func (s *Serializer) hasTaggedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup(s.tagKey); ok {
+			return true
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && s.hasTaggedFields(field.Type) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,13 +6,15 @@ import (
 )
 
 func TestIterateStruct(t *testing.T) {
-	shimIterFunc := iterFunc(func(value reflect.Value, memberType memberType, memberNames ...string) error { return nil })
+	shimIterFunc := iterFunc(func(value reflect.Value, memberType memberType, options tagOptions, memberNames ...string) error {
+		return nil
+	})
 	shimMemberNames := []string{}
 
 	// test incorrectly passing a non pointer
 	notAPointer := 10
 	notAPointerError := "v must be a pointer"
-	if err := iterateStruct(notAPointer, shimIterFunc, shimMemberNames...); err == nil {
+	if err := defaultSerializer.iterateStruct(notAPointer, shimIterFunc, shimMemberNames...); err == nil {
 		t.Errorf("iterateStruct must error out if v is not a pointer")
 	} else {
 		if err.Error() != notAPointerError {
@@ -22,14 +24,14 @@ func TestIterateStruct(t *testing.T) {
 
 	// test passing a nil pointer
 	var nilPointer *int
-	if err := iterateStruct(nilPointer, shimIterFunc, shimMemberNames...); err != nil {
+	if err := defaultSerializer.iterateStruct(nilPointer, shimIterFunc, shimMemberNames...); err != nil {
 		t.Errorf("iterateStruct must not error out if passed a nil pointer, got error: %s", err.Error())
 	}
 
 	// test incorrectly passing a pointer to a non struct
 	notAPointerToAStruct := 10
 	notAPointerToAStructError := "v must be a pointer to a struct"
-	if err := iterateStruct(&notAPointerToAStruct, shimIterFunc, shimMemberNames...); err != nil {
+	if err := defaultSerializer.iterateStruct(&notAPointerToAStruct, shimIterFunc, shimMemberNames...); err != nil {
 		if err.Error() != notAPointerToAStructError {
 			t.Errorf("passing a pointer to a non struct to iterateStruct should error out with message: %s, but got: %s", notAPointerToAStructError, err.Error())
 		}
@@ -37,3 +39,26 @@ func TestIterateStruct(t *testing.T) {
 		t.Errorf("iterateStruct must error out if not passed a pointer to a struct, got no error")
 	}
 }
+
+func TestIterateStructIgnoredFields(t *testing.T) {
+	type IgnoredEmbedded struct {
+		Secret string `jsonapi:"attribute,secret"`
+	}
+	type Ignored struct {
+		ID              string `jsonapi:"primary,ignoreds"`
+		Name            string `jsonapi:"attribute,name"`
+		PasswordHash    string `jsonapi:"-"`
+		IgnoredEmbedded `jsonapi:"-"`
+	}
+	memberNames := []string{}
+	if err := defaultSerializer.iterateStruct(&Ignored{}, func(value reflect.Value, memberType memberType, options tagOptions, names ...string) error {
+		memberNames = append(memberNames, names...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"ignoreds", "name"}
+	if !reflect.DeepEqual(memberNames, expected) {
+		t.Errorf("expected member names: %v, got: %v", expected, memberNames)
+	}
+}
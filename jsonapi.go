@@ -1,24 +1,125 @@
 package jsonapi
 
-var (
-	jsonPrefix = ""
-	jsonIndent = "\t"
-	tagKey     = "jsonapi"
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
 )
 
-// SetJSONPrefix sets the prefix value for json.MarshalIndent.
+// Format is the formatting of JSON:API encodings.
+type Format int
+
+const (
+	// DefaultFormat formats encodings as configured with SetFormat.
+	DefaultFormat Format = iota
+	// CompactFormat encodes documents without insignificant white space.
+	CompactFormat
+	// PrettyFormat indents encodings with the JSON prefix and indent values.
+	PrettyFormat
+)
+
+// Serializer encodes and decodes JSON:API documents. Each Serializer owns its configuration and
+// custom (un)marshalers, so independent serializers can be used in the same program.
+type Serializer struct {
+	format     Format
+	jsonPrefix string
+	jsonIndent string
+	tagKey     string
+
+	maxBodySize int64
+
+	// codecsMu guards customMarshalers and customUnmarshalers, which can be registered while
+	// documents are being (un)marshaled.
+	codecsMu           sync.RWMutex
+	customMarshalers   map[reflect.Type]marshalerErrorFunc
+	customUnmarshalers map[reflect.Type]unmarshalerErrorFunc
+
+	// extensionsMu guards extensions and profiles, the supported JSON:API extension and profile
+	// URIs.
+	extensionsMu sync.RWMutex
+	extensions   map[string]bool
+	profiles     map[string]bool
+}
+
+// NewSerializer generates a new Serializer with the default configuration.
+func NewSerializer() *Serializer {
+	return &Serializer{
+		format:             CompactFormat,
+		jsonPrefix:         "",
+		jsonIndent:         "\t",
+		tagKey:             "jsonapi",
+		maxBodySize:        DefaultMaxBodySize,
+		customMarshalers:   make(map[reflect.Type]marshalerErrorFunc),
+		customUnmarshalers: make(map[reflect.Type]unmarshalerErrorFunc),
+		extensions:         make(map[string]bool),
+		profiles:           make(map[string]bool),
+	}
+}
+
+// defaultSerializer is the Serializer used by the package-level functions.
+var defaultSerializer = NewSerializer()
+
+// SetFormat sets the format of the encodings made without choosing one, which is CompactFormat by
+// default. DefaultFormat restores it.
+func SetFormat(format Format) {
+	defaultSerializer.SetFormat(format)
+}
+
+// SetFormat sets the format of the encodings made without choosing one, which is CompactFormat by
+// default. DefaultFormat restores it.
+func (s *Serializer) SetFormat(format Format) {
+	if format == DefaultFormat {
+		format = CompactFormat
+	}
+	s.format = format
+}
+
+// SetJSONPrefix sets the prefix value for json.MarshalIndent, used by PrettyFormat.
 // See https://golang.org/pkg/encoding/json/#MarshalIndent.
 func SetJSONPrefix(prefix string) {
-	jsonPrefix = prefix
+	defaultSerializer.SetJSONPrefix(prefix)
 }
 
-// SetJSONIndent sets the indent value for json.MarshalIndent.
-// See // See https://golang.org/pkg/encoding/json/#MarshalIndent.
+// SetJSONPrefix sets the prefix value for json.MarshalIndent, used by PrettyFormat.
+// See https://golang.org/pkg/encoding/json/#MarshalIndent.
+func (s *Serializer) SetJSONPrefix(prefix string) {
+	s.jsonPrefix = prefix
+}
+
+// SetJSONIndent sets the indent value for json.MarshalIndent, used by PrettyFormat.
+// See https://golang.org/pkg/encoding/json/#MarshalIndent.
 func SetJSONIndent(indent string) {
-	jsonIndent = indent
+	defaultSerializer.SetJSONIndent(indent)
+}
+
+// SetJSONIndent sets the indent value for json.MarshalIndent, used by PrettyFormat.
+// See https://golang.org/pkg/encoding/json/#MarshalIndent.
+func (s *Serializer) SetJSONIndent(indent string) {
+	s.jsonIndent = indent
 }
 
 // SetTagKey sets a custom value for the JSON:API tag key.
 func SetTagKey(key string) {
-	tagKey = key
+	defaultSerializer.SetTagKey(key)
+}
+
+// SetTagKey sets a custom value for the JSON:API tag key.
+func (s *Serializer) SetTagKey(key string) {
+	s.tagKey = key
+}
+
+// formatOf returns the format of an encoding with p.
+func (s *Serializer) formatOf(p *MarshalParams) Format {
+	if p != nil && p.Format != DefaultFormat {
+		return p.Format
+	}
+	return s.format
+}
+
+// encodeJSON returns the JSON encoding of v in format.
+func (s *Serializer) encodeJSON(v interface{}, format Format) ([]byte, error) {
+	if format == PrettyFormat {
+		return json.MarshalIndent(v, s.jsonPrefix, s.jsonIndent)
+	}
+	return json.Marshal(v)
 }
@@ -4,6 +4,19 @@ package jsonapi
 // a the top-level document.
 // See https://jsonapi.org/format/#document-jsonapi-object.
 type Information struct {
-	Version string `json:"version,omitempty"`
-	Meta    Meta   `json:"meta,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Ext     []string `json:"ext,omitempty"`
+	Profile []string `json:"profile,omitempty"`
+	Meta    Meta     `json:"meta,omitempty"`
+}
+
+// setMediaType lists the extensions and profiles applied to a document, introduced by JSON:API
+// version 1.1.
+func (i *Information) setMediaType(ext, profile []string) {
+	if len(ext) == 0 && len(profile) == 0 {
+		return
+	}
+	i.Version = "1.1"
+	i.Ext = ext
+	i.Profile = profile
 }
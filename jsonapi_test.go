@@ -2,35 +2,104 @@ package jsonapi
 
 import (
 	"bytes"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestSetJSONPrefix(t *testing.T) {
 	def := ""
-	if def != jsonPrefix {
-		t.Errorf("default jsonPrefix was incorrect, got: %s, want: %s.", jsonPrefix, "")
+	if def != defaultSerializer.jsonPrefix {
+		t.Errorf("default jsonPrefix was incorrect, got: %s, want: %s.", defaultSerializer.jsonPrefix, "")
 	}
 	space := " "
 	SetJSONPrefix(space)
-	if space != jsonPrefix {
-		t.Errorf("space jsonPrefix was incorrect, got: %s, want: %s.", jsonPrefix, " ")
+	if space != defaultSerializer.jsonPrefix {
+		t.Errorf("space jsonPrefix was incorrect, got: %s, want: %s.", defaultSerializer.jsonPrefix, " ")
 	}
 	SetJSONPrefix(def)
 }
 
 func TestSetJSONIndent(t *testing.T) {
 	def := "\t"
-	if def != jsonIndent {
-		t.Errorf("default jsonIndent was incorrect, got: %s, want: %s.", jsonIndent, "\t")
+	if def != defaultSerializer.jsonIndent {
+		t.Errorf("default jsonIndent was incorrect, got: %s, want: %s.", defaultSerializer.jsonIndent, "\t")
 	}
 	space := " "
 	SetJSONIndent(space)
-	if space != jsonIndent {
-		t.Errorf("space jsonIndent was incorrect, got: %s, want: %s.", jsonIndent, " ")
+	if space != defaultSerializer.jsonIndent {
+		t.Errorf("space jsonIndent was incorrect, got: %s, want: %s.", defaultSerializer.jsonIndent, " ")
 	}
 	SetJSONIndent(def)
 }
 
+func TestSetFormat(t *testing.T) {
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute,title"`
+	}
+	article := Article{
+		ID:    "article-id",
+		Title: "Hello World!",
+	}
+	compact := []byte(`{"data":{"id":"article-id","type":"articles","attributes":{"title":"Hello World!"}},"jsonapi":{"version":"1.0"}}`)
+	pretty := []byte(`{
+	"data": {
+		"id": "article-id",
+		"type": "articles",
+		"attributes": {
+			"title": "Hello World!"
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+
+	s := NewSerializer()
+	type formatTest struct {
+		format   Format
+		params   *MarshalParams
+		expected []byte
+	}
+	tests := []formatTest{
+		{format: DefaultFormat, params: nil, expected: compact},
+		{format: DefaultFormat, params: &MarshalParams{Format: PrettyFormat}, expected: pretty},
+		{format: PrettyFormat, params: nil, expected: pretty},
+		{format: PrettyFormat, params: &MarshalParams{Format: CompactFormat}, expected: compact},
+		{format: DefaultFormat, params: nil, expected: compact},
+		{format: CompactFormat, params: &MarshalParams{}, expected: compact},
+	}
+	for _, test := range tests {
+		s.SetFormat(test.format)
+		got, err := s.Marshal(&article, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Compare(got, test.expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(test.expected), string(got))
+		}
+	}
+
+	// test DefaultFormat restores the default format
+	s.SetFormat(PrettyFormat)
+	s.SetFormat(DefaultFormat)
+	if s.format != CompactFormat {
+		t.Errorf("expected format: %d, got: %d", CompactFormat, s.format)
+	}
+
+	// test errors documents
+	got, err := s.MarshalErrors(nil, Error{Status: "404"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte(`{"jsonapi":{"version":"1.0"},"errors":[{"status":"404"}]}`)
+	if bytes.Compare(got, expected) != 0 {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+	}
+}
+
 func TestSetTagKey(t *testing.T) {
 	SetTagKey("customKey")
 	type Article struct {
@@ -53,7 +122,7 @@ func TestSetTagKey(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&article, nil); err != nil {
+	if got, err := Marshal(&article, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, articleExpected) != 0 {
@@ -63,3 +132,112 @@ func TestSetTagKey(t *testing.T) {
 	// TODO fix this so we don't have to reset the key
 	SetTagKey("jsonapi")
 }
+
+func TestSerializer(t *testing.T) {
+	type Article struct {
+		ID    string `customKey:"primary,articles"`
+		Title string `customKey:"attribute,title"`
+	}
+	s := NewSerializer()
+	s.SetTagKey("customKey")
+	s.SetFormat(PrettyFormat)
+	s.SetJSONIndent("  ")
+	s.RegisterMarshaler(reflect.TypeOf(""), func(search map[string]interface{}, memberName string, value reflect.Value) {
+		search[memberName] = strings.ToUpper(value.String())
+	})
+	article := Article{
+		ID:    "article-id",
+		Title: "Hello World!",
+	}
+	articleExpected := []byte(`{
+  "data": {
+    "id": "article-id",
+    "type": "articles",
+    "attributes": {
+      "title": "HELLO WORLD!"
+    }
+  },
+  "jsonapi": {
+    "version": "1.0"
+  }
+}`)
+	got, err := s.Marshal(&article, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(got, articleExpected) != 0 {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", string(articleExpected), string(got))
+	}
+	unmarshaled := Article{}
+	if err := s.Unmarshal(got, &unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if unmarshaled != (Article{ID: "article-id", Title: "HELLO WORLD!"}) {
+		t.Errorf("expected article: %+v, got: %+v", article, unmarshaled)
+	}
+
+	// the default serializer must not be affected
+	if defaultSerializer.tagKey != "jsonapi" || defaultSerializer.jsonIndent != "\t" {
+		t.Errorf("expected default serializer configuration to be unchanged, got tag key: %s, indent: %q", defaultSerializer.tagKey, defaultSerializer.jsonIndent)
+	}
+	if _, ok := defaultSerializer.customMarshaler(reflect.TypeOf("")); ok {
+		t.Errorf("expected default serializer to not have the custom marshaler registered")
+	}
+}
+
+func TestSerializerCodecsConcurrency(t *testing.T) {
+	type Temperature float64
+	type Reading struct {
+		ID          string      `jsonapi:"primary,readings"`
+		Temperature Temperature `jsonapi:"attribute,temperature"`
+	}
+	s := NewSerializer()
+	temperatureType := reflect.TypeOf(Temperature(0))
+	input := []byte(`{
+	"data": {
+		"id": "reading-1",
+		"type": "readings",
+		"attributes": {
+			"temperature": 21.5
+		}
+	}
+}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			s.RegisterMarshaler(temperatureType, func(search map[string]interface{}, memberName string, value reflect.Value) {
+				search[memberName] = value.Float()
+			})
+			s.RegisterUnmarshaler(temperatureType, func(v interface{}, value reflect.Value) {
+				value.SetFloat(v.(float64))
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := s.Marshal(&Reading{ID: "reading-1", Temperature: 21.5}, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := s.Unmarshal(input, &Reading{}); err != nil {
+				t.Error(err)
+			}
+			s.UnregisterMarshaler(temperatureType)
+			s.UnregisterUnmarshaler(temperatureType)
+		}()
+	}
+	wg.Wait()
+
+	s.UnregisterMarshaler(temperatureType)
+	s.UnregisterUnmarshaler(temperatureType)
+	if _, ok := s.customMarshaler(temperatureType); ok {
+		t.Error("expected custom marshaler to be unregistered")
+	}
+	if _, ok := s.customUnmarshaler(temperatureType); ok {
+		t.Error("expected custom unmarshaler to be unregistered")
+	}
+}
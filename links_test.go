@@ -40,7 +40,8 @@ func TestDocumentLinks(t *testing.T) {
 	}
 }`)
 	if b, err := Marshal(&t1, &MarshalParams{
-		Links: &links,
+		Format: PrettyFormat,
+		Links:  &links,
 	}); err != nil {
 		t.Errorf(err.Error())
 	} else {
@@ -84,7 +85,7 @@ func TestResourceLinks(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&t1, nil); err != nil {
+	if b, err := Marshal(&t1, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -132,7 +133,7 @@ func TestResourceWithEmbeddedLinks(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&c, nil); err != nil {
+	if b, err := Marshal(&c, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -224,6 +225,7 @@ func TestMarshalRelationshipLinksMeta(t *testing.T) {
 	]
 }`)
 	if got, err := Marshal(&cosmos, &MarshalParams{
+		Format: PrettyFormat,
 		Links: &Links{
 			"prev": "/books/brocas-brain",
 			"next": "/books/pale-blue-dot",
@@ -336,6 +338,7 @@ func TestMarshalCompoundRelationshipLinksMeta(t *testing.T) {
 	]
 }`)
 	if got, err := Marshal(&cosmos, &MarshalParams{
+		Format: PrettyFormat,
 		Links: &Links{
 			"prev": "/books/brocas-brain",
 			"next": "/books/pale-blue-dot",
@@ -1,19 +1,93 @@
 package jsonapi
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 // MarshalParams are the optional parameters to add links and meta objects to a top-level document.
 type MarshalParams struct {
 	Links *Links
 	Meta  *Meta
+
+	// Include are the dotted relationship paths (e.g.: "comments.author") of the related resources
+	// to add to included, other relationships only hold resource linkage. When nil, every related
+	// resource is included. See ParseInclude.
+	Include []string
+
+	// Fields are the sparse fieldsets keyed by resource type, restricting the attribute and
+	// relationship members marshaled for resources of that type. See ParseFields.
+	Fields map[string][]string
+
+	// AllowMissingID allows resources with an empty id, e.g.: in the body of a request creating a
+	// resource whose id is generated by the server.
+	AllowMissingID bool
+
+	// Ext and Profile are the URIs of the JSON:API extensions and profiles applied to the document,
+	// listed in its jsonapi object. Respond sets them from the negotiated media type when empty.
+	Ext     []string
+	Profile []string
+
+	// Format overrides the format of the encoding, see SetFormat.
+	Format Format
 }
 
 // Marshal returns the JSON:API encoding of v.
 func Marshal(v interface{}, p *MarshalParams) ([]byte, error) {
+	return defaultSerializer.Marshal(v, p)
+}
+
+// Marshal returns the JSON:API encoding of v.
+func (s *Serializer) Marshal(v interface{}, p *MarshalParams) ([]byte, error) {
+	document, err := s.newDocument(v, p)
+	if err != nil {
+		return nil, err
+	}
+	return s.encodeJSON(document, s.formatOf(p))
+}
+
+// newDocument returns the top-level document of v, a *Document or a *CompoundDocument when v is a
+// slice.
+func (s *Serializer) newDocument(v interface{}, p *MarshalParams) (interface{}, error) {
+	data, err := s.newPrimaryData(v, p)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*Resource, len(data.values))
+	for i := range resources {
+		if resources[i], err = data.marshal(i); err != nil {
+			return nil, err
+		}
+	}
+	if data.isSlice {
+		return &CompoundDocument{
+			Data:     resources,
+			document: *data.document,
+		}, nil
+	}
+	return &Document{
+		Data:     resources[0],
+		document: *data.document,
+	}, nil
+}
+
+// primaryData marshals the primary data of a document one resource at a time, adding the related
+// resources it reaches to the included member of document.
+type primaryData struct {
+	state    *marshalState
+	document *document
+	include  includeTree
+	values   []interface{}
+	isSlice  bool
+}
+
+// newPrimaryData returns the primary data of v, with the top-level document members other than
+// data set from p.
+func (s *Serializer) newPrimaryData(v interface{}, p *MarshalParams) (*primaryData, error) {
 	rType := reflect.TypeOf(v)
 
 	// only allow pointer or slice kind
@@ -28,149 +102,250 @@ func Marshal(v interface{}, p *MarshalParams) ([]byte, error) {
 		isSlice = true
 	}
 
-	// handle compound document
-	if isSlice {
-		ncdp := &NewCompoundDocumentParams{}
-		if p != nil {
-			ncdp.Links = p.Links
-			ncdp.Meta = p.Meta
-		}
-		document := NewCompoundDocument(ncdp)
-		return marshalCompoundDocument(v, document)
-	}
-
-	// handle single document
+	// build include paths tree and fieldsets
+	var include includeTree
+	var fields fieldsets
+	allowMissingID := false
 	ndp := &NewDocumentParams{}
 	if p != nil {
+		allowMissingID = p.AllowMissingID
+		if p.Include != nil {
+			var err error
+			if include, err = s.newIncludeTree(rType, p.Include); err != nil {
+				return nil, err
+			}
+		}
+		fields = newFieldsets(p.Fields)
 		ndp.Links = p.Links
 		ndp.Meta = p.Meta
 	}
-	document := NewDocument(ndp)
-	return marshalDocument(v, document)
+	d := &NewDocument(ndp).document
+	if p != nil {
+		d.JSONAPI.setMediaType(p.Ext, p.Profile)
+	}
+	data := &primaryData{
+		state:    newMarshalState(s, d, fields, allowMissingID),
+		document: d,
+		include:  include,
+		isSlice:  isSlice,
+	}
+
+	// handle single document
+	if !isSlice {
+		data.values = []interface{}{v}
+		return data, data.register(reflect.ValueOf(v))
+	}
+
+	// handle compound document, registering primary data first so it's never repeated in included
+	values := reflect.ValueOf(v).Elem()
+	data.values = make([]interface{}, values.Len())
+	for i := range data.values {
+		value := values.Index(i)
+		if value.Kind() != reflect.Ptr {
+			return nil, fmt.Errorf("document must be pointer or slice of pointers")
+		}
+		if err := data.register(value); err != nil {
+			return nil, err
+		}
+		data.values[i] = value.Interface()
+	}
+	return data, nil
+}
+
+// register marks the resource of pointer value as present in the document.
+func (d *primaryData) register(value reflect.Value) error {
+	identifier, err := d.state.marshalIdentifier(value.Interface())
+	if err != nil {
+		return err
+	}
+	d.state.resources[markKey(identifier, value)] = true
+	return nil
+}
+
+// marshal returns the resource object of the i-th primary data value.
+func (d *primaryData) marshal(i int) (*Resource, error) {
+	return d.state.marshalResource(d.values[i], d.include)
 }
 
 // RegisterMarshaler register a custom marshaller function for a t type.
 func RegisterMarshaler(t reflect.Type, u marshalerFunc) {
-	customMarshalers[t] = u
+	defaultSerializer.RegisterMarshaler(t, u)
+}
+
+// RegisterMarshaler register a custom marshaller function for a t type.
+func (s *Serializer) RegisterMarshaler(t reflect.Type, u marshalerFunc) {
+	s.RegisterMarshalerFunc(t, func(search map[string]interface{}, memberName string, value reflect.Value) error {
+		u(search, memberName, value)
+		return nil
+	})
+}
+
+// RegisterMarshalerFunc register a custom marshaller function for a t type. Errors returned by u
+// are returned by Marshal annotated with the resource type and member name.
+func RegisterMarshalerFunc(t reflect.Type, u marshalerErrorFunc) {
+	defaultSerializer.RegisterMarshalerFunc(t, u)
+}
+
+// RegisterMarshalerFunc register a custom marshaller function for a t type. Errors returned by u
+// are returned by Marshal annotated with the resource type and member name.
+func (s *Serializer) RegisterMarshalerFunc(t reflect.Type, u marshalerErrorFunc) {
+	s.codecsMu.Lock()
+	defer s.codecsMu.Unlock()
+	s.customMarshalers[t] = u
+}
+
+// UnregisterMarshaler removes the custom marshaller function registered for t type, if any.
+func UnregisterMarshaler(t reflect.Type) {
+	defaultSerializer.UnregisterMarshaler(t)
+}
+
+// UnregisterMarshaler removes the custom marshaller function registered for t type, if any.
+func (s *Serializer) UnregisterMarshaler(t reflect.Type) {
+	s.codecsMu.Lock()
+	defer s.codecsMu.Unlock()
+	delete(s.customMarshalers, t)
+}
+
+func (s *Serializer) customMarshaler(t reflect.Type) (marshalerErrorFunc, bool) {
+	s.codecsMu.RLock()
+	defer s.codecsMu.RUnlock()
+	cm, ok := s.customMarshalers[t]
+	return cm, ok
 }
 
 type marshalerFunc = func(map[string]interface{}, string, reflect.Value)
 
-var customMarshalers = make(map[reflect.Type]marshalerFunc)
+type marshalerErrorFunc = func(map[string]interface{}, string, reflect.Value) error
 
-func marshalDocument(v interface{}, d *Document) ([]byte, error) {
-	d.Data = NewResource()
-	if err := iterateStruct(v, func(value reflect.Value, memberType memberType, memberNames ...string) error {
-		switch memberType {
-		case memberTypePrimary:
-			return d.Data.SetIDAndType(value, memberNames[0])
-		case memberTypeLinks:
-			return d.Data.SetLinks(value)
-		case memberTypeRelationship:
-			if d.Data.Relationships == nil {
-				d.Data.Relationships = Relationships{}
-			}
-			relIsSlice := false
-			if value.Kind() == reflect.Slice {
-				relIsSlice = true
-			}
-			if !relIsSlice {
-				if err := marshalRelationship(value, &d.document, d.Data, memberNames); err != nil {
-					return err
-				}
-			} else {
-				if err := marshalCompoundRelationship(value, &d.document, d.Data, memberNames); err != nil {
-					return err
-				}
-			}
+// marshalState holds the top-level document being built while walking a resource graph.
+type marshalState struct {
+	*Serializer
+	document *document
+	// resources holds the markKey of every resource already present in data or included.
+	resources      map[interface{}]bool
+	fields         fieldsets
+	allowMissingID bool
+}
+
+func newMarshalState(s *Serializer, d *document, fields fieldsets, allowMissingID bool) *marshalState {
+	return &marshalState{
+		Serializer:     s,
+		document:       d,
+		resources:      make(map[interface{}]bool),
+		fields:         fields,
+		allowMissingID: allowMissingID,
+	}
+}
+
+// marshalIdentifier returns a resource object holding only the id and type of v.
+func (s *marshalState) marshalIdentifier(v interface{}) (*Resource, error) {
+	identifier := &Resource{}
+	if err := s.iterateStruct(v, func(value reflect.Value, memberType memberType, options tagOptions, memberNames ...string) error {
+		// the first primary member declares the resource, embedded structs may have their own
+		if memberType != memberTypePrimary || identifier.Type != "" {
 			return nil
-		default:
-			return marshal(d.Data, memberType, memberNames, value)
 		}
+		return s.setIDAndType(identifier, value, memberNames[0])
 	}); err != nil {
 		return nil, err
 	}
-	return json.MarshalIndent(&d, jsonPrefix, jsonIndent)
+	return identifier, nil
 }
 
-func marshalCompoundDocument(v interface{}, cd *CompoundDocument) ([]byte, error) {
-	rValue := reflect.ValueOf(v)
-	values := rValue.Elem()
-	for i := 0; i < values.Len(); i++ {
-		value := values.Index(i)
-		if value.Kind() != reflect.Ptr {
-			return nil, fmt.Errorf("document must be pointer or slice of pointers")
+// setIDAndType sets the id and type of resource r, leaving its id empty if missing ids are allowed.
+func (s *marshalState) setIDAndType(r *Resource, idValue reflect.Value, resourceType string) error {
+	if s.allowMissingID {
+		id, err := formatID(idValue)
+		if err != nil {
+			return err
 		}
-		r := NewResource()
-		if err := iterateStruct(value.Interface(), func(value reflect.Value, memberType memberType, memberNames ...string) error {
-			switch memberType {
-			case memberTypePrimary:
-				return r.SetIDAndType(value, memberNames[0])
-			case memberTypeLinks:
-				return r.SetLinks(value)
-			case memberTypeRelationship:
-				if r.Relationships == nil {
-					r.Relationships = Relationships{}
-				}
-				relIsSlice := false
-				if value.Kind() == reflect.Slice {
-					relIsSlice = true
-				}
-				if !relIsSlice {
-					if err := marshalRelationship(value, &cd.document, r, memberNames); err != nil {
-						return err
-					}
-				} else {
-					if err := marshalCompoundRelationship(value, &cd.document, r, memberNames); err != nil {
-						return err
-					}
-				}
-				return nil
-			default:
-				return marshal(r, memberType, memberNames, value)
+		if id == "" {
+			if resourceType == "" {
+				return fmt.Errorf("type must be set")
 			}
-		}); err != nil {
-			return nil, err
+			r.Type = resourceType
+			return nil
 		}
-		cd.Data = append(cd.Data, r)
 	}
-	return json.MarshalIndent(&cd, jsonPrefix, jsonIndent)
+	return r.SetIDAndType(idValue, resourceType)
 }
 
-func marshalRelationship(value reflect.Value, d *document, r *Resource, memberNames []string) error {
-	rel := NewRelationship()
-	r.Relationships[memberNames[0]] = rel
-	if value.IsNil() {
-		return nil
+func (s *marshalState) marshalResource(v interface{}, include includeTree) (*Resource, error) {
+	// the resource type must be known before its members to apply sparse fieldsets
+	resourceType := ""
+	if s.fields != nil {
+		identifier, err := s.marshalIdentifier(v)
+		if err != nil {
+			return nil, err
+		}
+		resourceType = identifier.Type
 	}
-	newIncl := NewResource()
-	rel.AddResource(NewResource())
-	if err := iterateStruct(value.Interface(), func(v2 reflect.Value, memberType memberType, memberNames ...string) error {
+
+	r := NewResource()
+	if err := s.iterateStruct(v, func(value reflect.Value, memberType memberType, options tagOptions, memberNames ...string) error {
+		// skip empty members tagged with omitempty
+		if memberType != memberTypePrimary && options.Contains("omitempty") && isEmptyValue(value) {
+			return nil
+		}
 		switch memberType {
 		case memberTypePrimary:
-			if err := rel.Data.SetIDAndType(v2, memberNames[0]); err != nil {
-				return err
+			if r.Type != "" {
+				return nil
 			}
-			return newIncl.SetIDAndType(v2, memberNames[0])
+			return s.setIDAndType(r, value, memberNames[0])
 		case memberTypeLinks:
-			return newIncl.SetLinks(v2)
+			return r.SetLinks(value)
+		case memberTypeRelationship:
+			allowed := s.fields.allows(resourceType, memberNames[0])
+			// without include paths, related resources are only reached through marshaled relationships
+			if !allowed && include == nil {
+				return nil
+			}
+			if r.Relationships == nil {
+				r.Relationships = Relationships{}
+			}
+			var err error
+			switch value.Kind() {
+			case reflect.Slice:
+				err = s.marshalCompoundRelationship(value, r, memberNames, include)
+			case reflect.Ptr:
+				err = s.marshalRelationship(value, r, memberNames, include)
+			default:
+				return fmt.Errorf("relationship must be pointer or slice of pointers")
+			}
+			if !allowed {
+				delete(r.Relationships, memberNames[0])
+			}
+			return err
+		case memberTypeAttribute:
+			if !s.fields.allows(resourceType, memberNames[0]) {
+				return nil
+			}
+			return s.marshal(r, memberType, memberNames, options, value)
 		default:
-			return marshal(newIncl, memberType, memberNames, v2)
+			return s.marshal(r, memberType, memberNames, options, value)
 		}
 	}); err != nil {
-		return err
+		return nil, err
 	}
-	// make sure it's only added once
-	for _, incl := range d.Included {
-		if incl.Type == newIncl.Type && incl.ID == newIncl.ID {
-			return nil
-		}
+	return r, nil
+}
+
+func (s *marshalState) marshalRelationship(value reflect.Value, r *Resource, memberNames []string, include includeTree) error {
+	rel := NewRelationship()
+	r.Relationships[memberNames[0]] = rel
+	if value.IsNil() {
+		return nil
+	}
+	identifier, err := s.marshalRelated(value, memberNames[0], include)
+	if err != nil {
+		return err
 	}
-	d.Included = append(d.Included, newIncl)
+	rel.AddResource(identifier)
 	return nil
 }
 
-func marshalCompoundRelationship(value reflect.Value, d *document, r *Resource, memberNames []string) error {
+func (s *marshalState) marshalCompoundRelationship(value reflect.Value, r *Resource, memberNames []string, include includeTree) error {
 	rels := NewCompoundRelationship()
 	r.Relationships[memberNames[0]] = rels
 	for i := 0; i < value.Len(); i++ {
@@ -178,40 +353,72 @@ func marshalCompoundRelationship(value reflect.Value, d *document, r *Resource,
 		if sValue.Kind() != reflect.Ptr {
 			return fmt.Errorf("relationship must be pointer or slice of pointers")
 		}
-		newIncl := NewResource()
-		newRel := NewRelationship()
-		newRel.AddResource(NewResource())
-		if err := iterateStruct(sValue.Interface(), func(v2 reflect.Value, memberType memberType, memberNames ...string) error {
-			switch memberType {
-			case memberTypePrimary:
-				if err := newRel.Data.SetIDAndType(v2, memberNames[0]); err != nil {
-					return err
-				}
-				return newIncl.SetIDAndType(v2, memberNames[0])
-			case memberTypeLinks:
-				return newIncl.SetLinks(v2)
-			default:
-				return marshal(newIncl, memberType, memberNames, v2)
-			}
-		}); err != nil {
+		if sValue.IsNil() {
+			continue
+		}
+		identifier, err := s.marshalRelated(sValue, memberNames[0], include)
+		if err != nil {
 			return err
 		}
-		// make sure it's only added once to included
-		existsInIncluded := false
-		for _, incl := range d.Included {
-			if incl.Type == newIncl.Type && incl.ID == newIncl.ID {
-				existsInIncluded = true
+		rels.Data = append(rels.Data, identifier)
+	}
+	return nil
+}
+
+// marshalRelated returns the resource identifier of the related resource value, adding it to
+// included when the relationship named memberName is part of the include paths.
+func (s *marshalState) marshalRelated(value reflect.Value, memberName string, include includeTree) (*Resource, error) {
+	if include == nil {
+		return s.marshalIncluded(value, nil)
+	}
+	if child, ok := include[memberName]; ok {
+		return s.marshalIncluded(value, child)
+	}
+	return s.marshalIdentifier(value.Interface())
+}
+
+// markKey returns the key marking the resource of pointer value with identifier as already present
+// in the document: its type and id, or the pointer itself when it has no id, so resources without
+// ids aren't mistaken for one another.
+func markKey(identifier *Resource, value reflect.Value) interface{} {
+	if identifier.ID == "" {
+		return value.Pointer()
+	}
+	return identifier.key()
+}
+
+// marshalIncluded adds the related resource value, and recursively its own related resources, to
+// included. Resources already in the document are skipped, which also breaks relationship cycles.
+// It returns the resource identifier to use as relationship linkage.
+func (s *marshalState) marshalIncluded(value reflect.Value, include includeTree) (*Resource, error) {
+	identifier, err := s.marshalIdentifier(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	key := markKey(identifier, value)
+	if s.resources[key] {
+		// it may have been reached through a shorter path, so follow the remaining include paths
+		if len(include) > 0 {
+			if _, err := s.marshalResource(value.Interface(), include); err != nil {
+				return nil, err
 			}
 		}
-		if !existsInIncluded {
-			d.Included = append(d.Included, newIncl)
-		}
-		rels.Data = append(rels.Data, newRel.Data)
+		return identifier, nil
 	}
-	return nil
+	s.resources[key] = true
+
+	// reserve its position so included resources are ordered as found
+	i := len(s.document.Included)
+	s.document.Included = append(s.document.Included, nil)
+	included, err := s.marshalResource(value.Interface(), include)
+	if err != nil {
+		return nil, err
+	}
+	s.document.Included[i] = included
+	return identifier, nil
 }
 
-func marshal(resource *Resource, memberType memberType, memberNames []string, value reflect.Value) error {
+func (s *Serializer) marshal(resource *Resource, memberType memberType, memberNames []string, options tagOptions, value reflect.Value) error {
 	// figure out search
 	var search map[string]interface{}
 	switch memberType {
@@ -223,56 +430,205 @@ func marshal(resource *Resource, memberType memberType, memberNames []string, va
 		// TODO we should not need to skip relationships, this function should not be called for relationships
 		return nil
 	}
+	return s.marshalMember(search, resource, memberNames, options, value)
+}
 
-	// iterate memberNames
-	var memberName string
-	for i, name := range memberNames {
-		memberName = name
-		if i == len(memberNames)-1 {
-			break
-		}
-		// TODO do we still need this
-		// if _, ok := search[name]; !ok {
-		// 	search[name] = make(map[string]interface{})
-		// }
-		// search = search[name].(map[string]interface{})
-	}
-
-	// if pointer, get non-pointer kind
-	isPtr := false
-	kind := value.Kind()
-	if kind == reflect.Ptr {
-		isPtr = true
-		kind = reflect.New(value.Type().Elem()).Elem().Kind()
-	}
+// marshalMember sets the encoding of value in search, keyed by the last of memberNames.
+func (s *Serializer) marshalMember(search map[string]interface{}, resource *Resource, memberNames []string, options tagOptions, value reflect.Value) error {
+	memberName := memberNames[len(memberNames)-1]
 
-	// ignore nil pointers
-	if isPtr && value.IsNil() {
+	// ignore nil pointers and interfaces
+	if (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil() {
 		return nil
 	}
 
 	// use custom marshaller if exists
-	cm, hasCustomMarshaller := customMarshalers[value.Type()]
+	cm, hasCustomMarshaller := s.customMarshaler(value.Type())
 	if hasCustomMarshaller {
-		cm(search, memberName, value)
+		if err := cm(search, memberName, value); err != nil {
+			return newMemberError(resource.Type, memberNames, err)
+		}
 		return nil
 	}
 
 	// set value
-	switch kind {
+	v, ok, err := s.marshalValue(resource, memberNames, options, value)
+	if err != nil {
+		return err
+	}
+	if ok {
+		search[memberName] = v
+	}
+	// TODO handle error/warning for unsupported types
+	return nil
+}
+
+// marshalValue returns the attribute or meta encoding of value. ok is false when the kind of value
+// is not supported.
+func (s *Serializer) marshalValue(resource *Resource, memberNames []string, options tagOptions, value reflect.Value) (v interface{}, ok bool, err error) {
+	// encode nil pointers, interfaces and maps as null
+	if (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface || value.Kind() == reflect.Map) && value.IsNil() {
+		return nil, true, nil
+	}
+
+	// use custom marshaller if exists
+	if cm, hasCustomMarshaller := s.customMarshaler(value.Type()); hasCustomMarshaller {
+		search := map[string]interface{}{}
+		if err := cm(search, "value", value); err != nil {
+			return nil, true, newMemberError(resource.Type, memberNames, err)
+		}
+		return search["value"], true, nil
+	}
+
+	// handle time
+	if t, isTime := value.Interface().(time.Time); isTime {
+		if v, err = marshalTime(t, options); err != nil {
+			return nil, true, newMemberError(resource.Type, memberNames, err)
+		}
+		return v, true, nil
+	}
+
+	// use marshaling interfaces if implemented
+	if v, ok, err := marshalInterface(value); ok {
+		if err != nil {
+			return nil, true, newMemberError(resource.Type, memberNames, err)
+		}
+		return v, true, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return s.marshalValue(resource, memberNames, options, value.Elem())
+	case reflect.Struct:
+		v, err := s.marshalStruct(resource, memberNames, value)
+		return v, true, err
+	case reflect.Map:
+		v, err := s.marshalMap(resource, memberNames, options, value)
+		return v, true, err
+	case reflect.Slice, reflect.Array:
+		v, err := s.marshalSlice(resource, memberNames, options, value)
+		return v, true, err
 	case
 		reflect.Bool,
 		reflect.Complex64, reflect.Complex128,
 		reflect.Float32, reflect.Float64,
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Slice,
 		reflect.String,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Uintptr:
-		search[memberName] = value.Interface()
-		// TODO handle error/warning for unsupported types
-		// default:
-		// 	return fmt.Errorf("type: %+v, not supported, must implement custom marshaller", value.Type())
+		return value.Interface(), true, nil
 	}
-	return nil
+	return nil, false, nil
+}
+
+// marshalStruct returns the JSON object encoding of struct value, keyed by the member names of its
+// attribute and meta fields. Structs without tagged fields are encoded following encoding/json.
+func (s *Serializer) marshalStruct(resource *Resource, memberNames []string, value reflect.Value) (interface{}, error) {
+	if !s.hasTaggedFields(value.Type()) {
+		return value.Interface(), nil
+	}
+
+	// iterateStruct needs a pointer
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+
+	object := map[string]interface{}{}
+	if err := s.iterateStruct(ptr.Interface(), func(value reflect.Value, memberType memberType, options tagOptions, names ...string) error {
+		// nested structs are plain objects, they have no primary, links or relationship members
+		if memberType != memberTypeAttribute && memberType != memberTypeMeta {
+			return nil
+		}
+		if options.Contains("omitempty") && isEmptyValue(value) {
+			return nil
+		}
+		path := append(memberNames[:len(memberNames):len(memberNames)], names...)
+		return s.marshalMember(object, resource, path, options, value)
+	}); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// marshalSlice returns the JSON array encoding of slice or array value, encoding each element
+// following its type. Byte slices are encoded as base64 strings, as in encoding/json.
+func (s *Serializer) marshalSlice(resource *Resource, memberNames []string, options tagOptions, value reflect.Value) (interface{}, error) {
+	if value.Kind() == reflect.Slice && (value.IsNil() || value.Type().Elem().Kind() == reflect.Uint8) {
+		return value.Interface(), nil
+	}
+	array := make([]interface{}, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		path := append(memberNames[:len(memberNames):len(memberNames)], strconv.Itoa(i))
+		v, _, err := s.marshalValue(resource, path, options, value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		array[i] = v
+	}
+	return array, nil
+}
+
+// marshalMap returns the JSON object encoding of map value. Keys must be strings or implement
+// encoding.TextMarshaler, entries with unsupported values are skipped.
+func (s *Serializer) marshalMap(resource *Resource, memberNames []string, options tagOptions, value reflect.Value) (interface{}, error) {
+	object := make(map[string]interface{}, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		key, err := marshalMapKey(iter.Key())
+		if err != nil {
+			return nil, newMemberError(resource.Type, memberNames, err)
+		}
+
+		// map elements are not addressable, copy them so pointer receiver methods are found
+		elem := reflect.New(value.Type().Elem()).Elem()
+		elem.Set(iter.Value())
+
+		path := append(memberNames[:len(memberNames):len(memberNames)], key)
+		v, ok, err := s.marshalValue(resource, path, options, elem)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			object[key] = v
+		}
+	}
+	return object, nil
+}
+
+// marshalMapKey returns the JSON object key encoding of map key. Like encoding/json, integer keys
+// are encoded as decimal strings.
+func marshalMapKey(key reflect.Value) (string, error) {
+	if m, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("map key type: %s, not supported", key.Type())
+}
+
+// marshalInterface returns the value encoded by the AttributeMarshaler, json.Marshaler or
+// encoding.TextMarshaler implementation of value, in that order. ok is false when value doesn't
+// implement any of them.
+func marshalInterface(value reflect.Value) (v interface{}, ok bool, err error) {
+	if value.Kind() != reflect.Ptr && value.CanAddr() {
+		value = value.Addr()
+	}
+	switch m := value.Interface().(type) {
+	case AttributeMarshaler:
+		v, err = m.MarshalAttribute()
+		return v, true, err
+	case json.Marshaler:
+		b, err := m.MarshalJSON()
+		return json.RawMessage(b), true, err
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		return string(text), true, err
+	}
+	return nil, false, nil
 }
@@ -1,15 +1,26 @@
 package jsonapi
 
-import "encoding/json"
-
 // MarshalErrors returns the JSON:API errors encoding of errs.
 func MarshalErrors(p *MarshalParams, errs ...Error) ([]byte, error) {
+	return defaultSerializer.MarshalErrors(p, errs...)
+}
+
+// MarshalErrors returns the JSON:API errors encoding of errs.
+func (s *Serializer) MarshalErrors(p *MarshalParams, errs ...Error) ([]byte, error) {
+	return s.encodeJSON(newErrorsDocument(p, errs), s.formatOf(p))
+}
+
+// newErrorsDocument returns the top-level document of errs.
+func newErrorsDocument(p *MarshalParams, errs []Error) *Document {
 	ndp := NewDocumentParams{}
 	if p != nil {
 		ndp.Links = p.Links
 		ndp.Meta = p.Meta
 	}
 	document := NewDocument(&ndp)
+	if p != nil {
+		document.JSONAPI.setMediaType(p.Ext, p.Profile)
+	}
 	document.Errors = errs
-	return json.MarshalIndent(&document, jsonPrefix, jsonIndent)
+	return document
 }
@@ -24,7 +24,7 @@ func TestMarshalErrors(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := MarshalErrors(nil, simpleError); err != nil {
+	if b, err := MarshalErrors(&MarshalParams{Format: PrettyFormat}, simpleError); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(simpleErrorExpected, b) != 0 {
@@ -56,7 +56,7 @@ func TestMarshalErrors(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := MarshalErrors(nil, simpleErrorWithLinks); err != nil {
+	if b, err := MarshalErrors(&MarshalParams{Format: PrettyFormat}, simpleErrorWithLinks); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(simpleErrorWithLinksExpected, b) != 0 {
@@ -88,7 +88,7 @@ func TestMarshalErrors(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := MarshalErrors(nil, simpleErrorWithMeta); err != nil {
+	if b, err := MarshalErrors(&MarshalParams{Format: PrettyFormat}, simpleErrorWithMeta); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(simpleErrorWithMetaExpected, b) != 0 {
@@ -126,7 +126,7 @@ func TestMarshalErrors(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := MarshalErrors(nil, simpleErrorWithMetaAndLinks); err != nil {
+	if b, err := MarshalErrors(&MarshalParams{Format: PrettyFormat}, simpleErrorWithMetaAndLinks); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(simpleErrorWithMetaAndLinksExpected, b) != 0 {
@@ -159,6 +159,7 @@ func TestMarshalErrors(t *testing.T) {
 	]
 }`)
 	if b, err := MarshalErrors(&MarshalParams{
+		Format: PrettyFormat,
 		Links: &Links{
 			"about": "/errors/NOT_FOUND",
 		},
@@ -202,7 +203,7 @@ func TestMarshalManyErrors(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := MarshalErrors(nil, e1, e2); err != nil {
+	if b, err := MarshalErrors(&MarshalParams{Format: PrettyFormat}, e1, e2); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -2,7 +2,11 @@ package jsonapi
 
 import (
 	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -26,8 +30,12 @@ func TestMarshal(t *testing.T) {
 		"id": "someID",
 		"type": "samples",
 		"attributes": {
+			"embedded_string": "",
 			"float64": 3.14159265359,
 			"int": 99,
+			"nested": {
+				"nested_string": ""
+			},
 			"slice_ints": [
 				0,
 				1,
@@ -52,7 +60,7 @@ func TestMarshal(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	b, err := Marshal(&s, nil)
+	b, err := Marshal(&s, &MarshalParams{Format: PrettyFormat})
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -62,7 +70,7 @@ func TestMarshal(t *testing.T) {
 
 	// test incorrectly passing a non pointer to a struct
 	notPointerOrSliceError := "v must be pointer or slice"
-	if b, err := Marshal(s, nil); err == nil {
+	if b, err := Marshal(s, &MarshalParams{Format: PrettyFormat}); err == nil {
 		fmt.Println(string(b))
 		t.Errorf("marshal must error out if v is not a pointer or a slice")
 	} else {
@@ -93,7 +101,7 @@ func TestMarshalBool(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testTrue, nil); err != nil {
+	if b, err := Marshal(&testTrue, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedTrue, b) != 0 {
@@ -117,7 +125,7 @@ func TestMarshalBool(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testFalse, nil); err != nil {
+	if b, err := Marshal(&testFalse, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedFalse, b) != 0 {
@@ -149,7 +157,7 @@ func TestMarshalBoolPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testTrue, nil); err != nil {
+	if b, err := Marshal(&testTrue, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedTrue, b) != 0 {
@@ -173,7 +181,7 @@ func TestMarshalBoolPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testFalse, nil); err != nil {
+	if b, err := Marshal(&testFalse, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedFalse, b) != 0 {
@@ -194,7 +202,7 @@ func TestMarshalBoolPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testNil, nil); err != nil {
+	if b, err := Marshal(&testNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedNil, b) != 0 {
@@ -212,6 +220,7 @@ func TestMarshalCustomTypePtr(t *testing.T) {
 		ID  string                `jsonapi:"primary,test_custom_types"`
 		Foo *CustomNullableString `jsonapi:"attribute,bar"`
 	}
+	defer UnregisterMarshaler(reflect.TypeOf(&CustomNullableString{}))
 	RegisterMarshaler(reflect.TypeOf(&CustomNullableString{}), func(s map[string]interface{}, memberName string, value reflect.Value) {
 		if value.IsNil() {
 			return
@@ -243,7 +252,7 @@ func TestMarshalCustomTypePtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&t1, nil); err != nil {
+	if b, err := Marshal(&t1, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedValidString, b) != 0 {
@@ -270,7 +279,7 @@ func TestMarshalCustomTypePtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&t2, nil); err != nil {
+	if b, err := Marshal(&t2, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedValidEmptyString, b) != 0 {
@@ -297,7 +306,7 @@ func TestMarshalCustomTypePtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&t3, nil); err != nil {
+	if b, err := Marshal(&t3, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedNull, b) != 0 {
@@ -317,7 +326,7 @@ func TestMarshalCustomTypePtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&t4, nil); err != nil {
+	if b, err := Marshal(&t4, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedNil, b) != 0 {
@@ -348,7 +357,7 @@ func TestMarshalCustomTypeString(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&ts, nil); err != nil {
+	if b, err := Marshal(&ts, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -380,7 +389,7 @@ func TestMarshalCustomTypeStringPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&test, nil); err != nil {
+	if b, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -399,7 +408,7 @@ func TestMarshalCustomTypeStringPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testNil, nil); err != nil {
+	if b, err := Marshal(&testNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedNil, b) != 0 {
@@ -458,6 +467,7 @@ func TestMarshalCompound(t *testing.T) {
 	}
 }`)
 	if b, err := Marshal(&tcs, &MarshalParams{
+		Format: PrettyFormat,
 		Meta: &Meta{
 			"hello": "world!",
 		},
@@ -554,7 +564,7 @@ func TestMarshalCompoundWithRelationships(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := Marshal(&articles, nil); err != nil {
+	if b, err := Marshal(&articles, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -675,7 +685,7 @@ func TestMarshalCompoundWithCompoundRelationships(t *testing.T) {
 		}
 	]
 }`)
-	if b, err := Marshal(&articles, nil); err != nil {
+	if b, err := Marshal(&articles, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -705,7 +715,7 @@ func TestMarshalString(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&ts, nil); err != nil {
+	if b, err := Marshal(&ts, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -736,7 +746,7 @@ func TestMarshalStringPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&test, nil); err != nil {
+	if b, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expected, b) != 0 {
@@ -755,7 +765,7 @@ func TestMarshalStringPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if b, err := Marshal(&testNil, nil); err != nil {
+	if b, err := Marshal(&testNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(expectedNil, b) != 0 {
@@ -785,7 +795,7 @@ func TestMarshalInt(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -816,7 +826,7 @@ func TestMarshalIntPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -835,7 +845,7 @@ func TestMarshalIntPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -865,7 +875,7 @@ func TestMarshalInt8(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -896,7 +906,7 @@ func TestMarshalInt8Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -915,7 +925,7 @@ func TestMarshalInt8Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -945,7 +955,7 @@ func TestMarshalInt16(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -976,7 +986,7 @@ func TestMarshalInt16Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -995,7 +1005,7 @@ func TestMarshalInt16Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1025,7 +1035,7 @@ func TestMarshalInt32(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1056,7 +1066,7 @@ func TestMarshalInt32Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1075,7 +1085,7 @@ func TestMarshalInt32Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1105,7 +1115,7 @@ func TestMarshalInt64(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1136,7 +1146,7 @@ func TestMarshalInt64Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1155,7 +1165,7 @@ func TestMarshalInt64Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1185,7 +1195,7 @@ func TestMarshalUint(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1216,7 +1226,7 @@ func TestMarshalUintPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1235,7 +1245,7 @@ func TestMarshalUintPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1265,7 +1275,7 @@ func TestMarshalUint8(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1296,7 +1306,7 @@ func TestMarshalUint8Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1315,7 +1325,7 @@ func TestMarshalUint8Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1345,7 +1355,7 @@ func TestMarshalUint16(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1376,7 +1386,7 @@ func TestMarshalUint16Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1395,7 +1405,7 @@ func TestMarshalUint16Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1425,7 +1435,7 @@ func TestMarshalUint32(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1456,7 +1466,7 @@ func TestMarshalUint32Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1475,7 +1485,7 @@ func TestMarshalUint32Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1505,7 +1515,7 @@ func TestMarshalUint64(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1536,7 +1546,7 @@ func TestMarshalUint64Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1555,7 +1565,7 @@ func TestMarshalUint64Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1585,7 +1595,7 @@ func TestMarshalUintptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1616,7 +1626,7 @@ func TestMarshalUintptrPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1635,7 +1645,7 @@ func TestMarshalUintptrPtr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1665,7 +1675,7 @@ func TestMarshalFloat32(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1696,7 +1706,7 @@ func TestMarshalFloat32Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1715,7 +1725,7 @@ func TestMarshalFloat32Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1745,7 +1755,7 @@ func TestMarshalFloat64(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&seven, nil); err != nil {
+	if got, err := Marshal(&seven, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, want) != 0 {
@@ -1776,7 +1786,7 @@ func TestMarshalFloat64Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtr, nil); err != nil {
+	if got, err := Marshal(&sevenPtr, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedSeven) != 0 {
@@ -1795,7 +1805,7 @@ func TestMarshalFloat64Ptr(t *testing.T) {
 		"version": "1.0"
 	}
 }`)
-	if got, err := Marshal(&sevenPtrNil, nil); err != nil {
+	if got, err := Marshal(&sevenPtrNil, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expectedMissing) != 0 {
@@ -1880,7 +1890,7 @@ func TestMarshalRelationship(t *testing.T) {
 		}
 	]
 }`)
-	if got, err := Marshal(&test, nil); err != nil {
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expected) != 0 {
@@ -1940,7 +1950,7 @@ func TestMarshalRelationshipEmpty(t *testing.T) {
 		}
 	]
 }`)
-	if got, err := Marshal(&test, nil); err != nil {
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expected) != 0 {
@@ -2033,7 +2043,7 @@ func TestMarshalRelationshipArray(t *testing.T) {
 		}
 	]
 }`)
-	if got, err := Marshal(&test, nil); err != nil {
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expected) != 0 {
@@ -2093,7 +2103,7 @@ func TestMarshalRelationshipEmptyArray(t *testing.T) {
 		}
 	]
 }`)
-	if got, err := Marshal(&test, nil); err != nil {
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
 		t.Errorf(err.Error())
 	} else {
 		if bytes.Compare(got, expected) != 0 {
@@ -2106,7 +2116,7 @@ func TestMarshalErrors2(t *testing.T) {
 	// unmarshal non-pointer
 	nonPointerOrSliceErrMsg := "v must be pointer or slice"
 	nonPointerOrSlice := Sample{}
-	_, nonPointerOrSliceErr := Marshal(nonPointerOrSlice, nil)
+	_, nonPointerOrSliceErr := Marshal(nonPointerOrSlice, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case nonPointerOrSliceErr == nil:
 		t.Errorf("expected error: %s, but got no error", nonPointerOrSliceErrMsg)
@@ -2124,7 +2134,7 @@ func TestMarshalErrors2(t *testing.T) {
 		Foo: "bar",
 	}
 	missmissingTypeErrMsg := "type must be set"
-	_, missmissingTypeErr := Marshal(missingType, nil)
+	_, missmissingTypeErr := Marshal(missingType, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case missmissingTypeErr == nil:
 		t.Errorf("expected error: %s, but got no error", missmissingTypeErrMsg)
@@ -2142,7 +2152,7 @@ func TestMarshalErrors2(t *testing.T) {
 		Foo: "bar",
 	}
 	wrongIDTypeErrMsg := "ID must be a string or int, got bool"
-	_, wrongIDTypeErr := Marshal(wrongIDType, nil)
+	_, wrongIDTypeErr := Marshal(wrongIDType, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case wrongIDTypeErr == nil:
 		t.Errorf("expected error: %s, but got no error", wrongIDTypeErrMsg)
@@ -2165,7 +2175,7 @@ func TestMarshalErrors2(t *testing.T) {
 		},
 	}
 	wrongIDTypeInRelErrMsg := "ID must be a string or int, got bool"
-	_, wrongIDTypeInRelErr := Marshal(wrongIDTypeInRel, nil)
+	_, wrongIDTypeInRelErr := Marshal(wrongIDTypeInRel, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case wrongIDTypeInRelErr == nil:
 		t.Errorf("expected error: %s, but got no error", wrongIDTypeInRelErrMsg)
@@ -2191,7 +2201,7 @@ func TestMarshalErrors2(t *testing.T) {
 		},
 	}
 	wrongIDTypeInRelsErrMsg := "ID must be a string or int, got bool"
-	_, wrongIDTypeInRelsErr := Marshal(wrongIDTypeInRels, nil)
+	_, wrongIDTypeInRelsErr := Marshal(wrongIDTypeInRels, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case wrongIDTypeInRelsErr == nil:
 		t.Errorf("expected error: %s, but got no error", wrongIDTypeInRelsErrMsg)
@@ -2219,7 +2229,7 @@ func TestMarshalErrors2(t *testing.T) {
 		},
 	}
 	wrongIDTypesInRelsErrMsg := "ID must be a string or int, got bool"
-	_, wrongIDTypesInRelsErr := Marshal(wrongIDTypesInRels, nil)
+	_, wrongIDTypesInRelsErr := Marshal(wrongIDTypesInRels, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case wrongIDTypesInRelsErr == nil:
 		t.Errorf("expected error: %s, but got no error", wrongIDTypesInRelsErrMsg)
@@ -2251,7 +2261,7 @@ func TestMarshalErrors2(t *testing.T) {
 		},
 	}
 	wrongIDTypeInCompRelsInCompDocErrMsg := "ID must be a string or int, got bool"
-	_, wrongIDTypeInCompRelsInCompDocErr := Marshal(wrongIDTypeInCompRelsInCompDoc, nil)
+	_, wrongIDTypeInCompRelsInCompDocErr := Marshal(wrongIDTypeInCompRelsInCompDoc, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case wrongIDTypeInCompRelsInCompDocErr == nil:
 		t.Errorf("expected error: %s, but got no error", wrongIDTypeInCompRelsInCompDocErrMsg)
@@ -2267,7 +2277,7 @@ func TestMarshalErrors2(t *testing.T) {
 		},
 	}
 	nonPointerSliceErrMsg := "document must be pointer or slice of pointers"
-	_, nonPointerSliceErr := Marshal(&nonPointerSlice, nil)
+	_, nonPointerSliceErr := Marshal(&nonPointerSlice, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case nonPointerSliceErr == nil:
 		t.Errorf("expected error: %s, but got no error", nonPointerSliceErr)
@@ -2298,7 +2308,7 @@ func TestMarshalErrors2(t *testing.T) {
 		},
 	}
 	nonPointerCompoundRelsErrMsg := "relationship must be pointer or slice of pointers"
-	_, nonPointerCompoundRelsErr := Marshal(&nonPointerCompoundRels, nil)
+	_, nonPointerCompoundRelsErr := Marshal(&nonPointerCompoundRels, &MarshalParams{Format: PrettyFormat})
 	switch {
 	case nonPointerCompoundRelsErr == nil:
 		t.Errorf("expected error: %s, but got no error", nonPointerCompoundRelsErr)
@@ -2306,3 +2316,895 @@ func TestMarshalErrors2(t *testing.T) {
 		t.Errorf("expected error: %s, got: %s", nonPointerCompoundRelsErrMsg, nonPointerCompoundRelsErr.Error())
 	}
 }
+
+func TestMarshalNestedRelationships(t *testing.T) {
+	type Publisher struct {
+		ID   string `jsonapi:"primary,publishers"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	type Author struct {
+		ID        string     `jsonapi:"primary,authors"`
+		Name      string     `jsonapi:"attribute,name"`
+		Publisher *Publisher `jsonapi:"relationship,publisher"`
+	}
+	type Book struct {
+		ID      string    `jsonapi:"primary,books"`
+		Title   string    `jsonapi:"attribute,title"`
+		Authors []*Author `jsonapi:"relationship,authors"`
+	}
+	publisher := &Publisher{
+		ID:   "publisher-1",
+		Name: "Random House",
+	}
+	book := Book{
+		ID:    "book-1",
+		Title: "Cosmos",
+		Authors: []*Author{
+			{
+				ID:        "author-1",
+				Name:      "Carl",
+				Publisher: publisher,
+			},
+			{
+				ID:        "author-2",
+				Name:      "Ann",
+				Publisher: publisher,
+			},
+		},
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "book-1",
+		"type": "books",
+		"attributes": {
+			"title": "Cosmos"
+		},
+		"relationships": {
+			"authors": {
+				"data": [
+					{
+						"id": "author-1",
+						"type": "authors"
+					},
+					{
+						"id": "author-2",
+						"type": "authors"
+					}
+				]
+			}
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"included": [
+		{
+			"id": "author-1",
+			"type": "authors",
+			"attributes": {
+				"name": "Carl"
+			},
+			"relationships": {
+				"publisher": {
+					"data": {
+						"id": "publisher-1",
+						"type": "publishers"
+					}
+				}
+			}
+		},
+		{
+			"id": "publisher-1",
+			"type": "publishers",
+			"attributes": {
+				"name": "Random House"
+			}
+		},
+		{
+			"id": "author-2",
+			"type": "authors",
+			"attributes": {
+				"name": "Ann"
+			},
+			"relationships": {
+				"publisher": {
+					"data": {
+						"id": "publisher-1",
+						"type": "publishers"
+					}
+				}
+			}
+		}
+	]
+}`)
+	if got, err := Marshal(&book, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+}
+
+func TestMarshalCyclicRelationships(t *testing.T) {
+	author := &CyclicAuthor{
+		ID:   "author-1",
+		Name: "John",
+	}
+	article := &CyclicArticle{
+		ID:     "article-1",
+		Title:  "Hello world!",
+		Author: author,
+	}
+	author.Articles = []*CyclicArticle{article}
+	expected := []byte(`{
+	"data": {
+		"id": "article-1",
+		"type": "articles",
+		"attributes": {
+			"title": "Hello world!"
+		},
+		"relationships": {
+			"author": {
+				"data": {
+					"id": "author-1",
+					"type": "authors"
+				}
+			}
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"included": [
+		{
+			"id": "author-1",
+			"type": "authors",
+			"attributes": {
+				"name": "John"
+			},
+			"relationships": {
+				"articles": {
+					"data": [
+						{
+							"id": "article-1",
+							"type": "articles"
+						}
+					]
+				}
+			}
+		}
+	]
+}`)
+	if got, err := Marshal(article, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+}
+
+func TestMarshalOmitEmpty(t *testing.T) {
+	type Author struct {
+		ID   string `jsonapi:"primary,authors"`
+		Name string `jsonapi:"attribute,name,omitempty"`
+	}
+	type TestOmitEmpty struct {
+		ID       string            `jsonapi:"primary,test_omit_empties"`
+		String   string            `jsonapi:"attribute,string,omitempty"`
+		Int      int               `jsonapi:"attribute,int,omitempty"`
+		Bool     bool              `jsonapi:"attribute,bool,omitempty"`
+		Float64  float64           `jsonapi:"attribute,float64,omitempty"`
+		IntPtr   *int              `jsonapi:"attribute,int_ptr,omitempty"`
+		Slice    []string          `jsonapi:"attribute,slice,omitempty"`
+		Kept     string            `jsonapi:"attribute,kept"`
+		Meta     string            `jsonapi:"meta,meta,omitempty"`
+		Author   *Author           `jsonapi:"relationship,author,omitempty"`
+		Authors  []*Author         `jsonapi:"relationship,authors,omitempty"`
+		Editor   *Author           `jsonapi:"relationship,editor"`
+		Ignored  map[string]string `jsonapi:"attribute,ignored,omitempty,unknown_option"`
+		Included *Author           `jsonapi:"relationship,included,omitempty"`
+	}
+	zero := 0
+	empty := TestOmitEmpty{
+		ID: "someID",
+	}
+	expectedEmpty := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_omit_empties",
+		"attributes": {
+			"kept": ""
+		},
+		"relationships": {
+			"editor": {
+				"data": null
+			}
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&empty, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expectedEmpty) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expectedEmpty), string(got))
+		}
+	}
+
+	full := TestOmitEmpty{
+		ID:      "someID",
+		String:  "hello",
+		Int:     1,
+		Bool:    true,
+		Float64: 1.5,
+		IntPtr:  &zero,
+		Slice:   []string{"world"},
+		Kept:    "kept",
+		Meta:    "meta",
+		Authors: []*Author{
+			{
+				ID: "author-1",
+			},
+		},
+		Included: &Author{
+			ID:   "author-2",
+			Name: "John",
+		},
+	}
+	expectedFull := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_omit_empties",
+		"attributes": {
+			"bool": true,
+			"float64": 1.5,
+			"int": 1,
+			"int_ptr": 0,
+			"kept": "kept",
+			"slice": [
+				"world"
+			],
+			"string": "hello"
+		},
+		"relationships": {
+			"authors": {
+				"data": [
+					{
+						"id": "author-1",
+						"type": "authors"
+					}
+				]
+			},
+			"editor": {
+				"data": null
+			},
+			"included": {
+				"data": {
+					"id": "author-2",
+					"type": "authors"
+				}
+			}
+		},
+		"meta": {
+			"meta": "meta"
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"included": [
+		{
+			"id": "author-1",
+			"type": "authors"
+		},
+		{
+			"id": "author-2",
+			"type": "authors",
+			"attributes": {
+				"name": "John"
+			}
+		}
+	]
+}`)
+	if got, err := Marshal(&full, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expectedFull) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expectedFull), string(got))
+		}
+	}
+
+	// omitempty is ignored when unmarshaling
+	got := TestOmitEmpty{}
+	if err := Unmarshal(expectedFull, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.String != "hello" || got.Int != 1 || got.IntPtr == nil || *got.IntPtr != 0 || got.Meta != "meta" {
+		t.Errorf("expected omitempty members to be unmarshaled, got: %+v", got)
+	}
+	if got.Included == nil || got.Included.Name != "John" {
+		t.Errorf("expected omitempty relationship to be unmarshaled, got: %+v", got.Included)
+	}
+}
+
+func TestMarshalIgnoredField(t *testing.T) {
+	type TestIgnored struct {
+		ID           string `jsonapi:"primary,test_ignoreds"`
+		Name         string `jsonapi:"attribute,name"`
+		PasswordHash string `jsonapi:"-"`
+	}
+	test := TestIgnored{
+		ID:           "someID",
+		Name:         "John",
+		PasswordHash: "$2a$10$",
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_ignoreds",
+		"attributes": {
+			"name": "John"
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+}
+
+func TestMarshalCustomTypeError(t *testing.T) {
+	type Money struct {
+		Amount   int64
+		Currency string
+	}
+	type TestMoney struct {
+		ID    string `jsonapi:"primary,test_moneys"`
+		Price *Money `jsonapi:"attribute,price"`
+	}
+	errMissingCurrency := errors.New("missing currency")
+	RegisterMarshalerFunc(reflect.TypeOf(&Money{}), func(s map[string]interface{}, memberName string, value reflect.Value) error {
+		m := value.Interface().(*Money)
+		if m.Currency == "" {
+			return errMissingCurrency
+		}
+		s[memberName] = fmt.Sprintf("%d %s", m.Amount, m.Currency)
+		return nil
+	})
+	defer UnregisterMarshaler(reflect.TypeOf(&Money{}))
+
+	valid := TestMoney{
+		ID: "someID",
+		Price: &Money{
+			Amount:   100,
+			Currency: "USD",
+		},
+	}
+	expectedValid := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_moneys",
+		"attributes": {
+			"price": "100 USD"
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&valid, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expectedValid) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expectedValid), string(got))
+		}
+	}
+
+	_, err := Marshal(&TestMoney{ID: "someID", Price: &Money{Amount: 100}}, nil)
+	expectedErrMsg := "type: test_moneys, member: price, missing currency"
+	switch {
+	case err == nil:
+		t.Errorf("expected error: %s, but got no error", expectedErrMsg)
+	case err.Error() != expectedErrMsg:
+		t.Errorf("expected error: %s, got: %s", expectedErrMsg, err.Error())
+	case !errors.Is(err, errMissingCurrency):
+		t.Errorf("expected error to wrap: %s", errMissingCurrency)
+	}
+}
+
+// Point implements AttributeMarshaler and AttributeUnmarshaler.
+type Point struct {
+	X, Y float64
+}
+
+func (p Point) MarshalAttribute() (interface{}, error) {
+	return []float64{p.X, p.Y}, nil
+}
+
+func (p *Point) UnmarshalAttribute(v interface{}) error {
+	coordinates, ok := v.([]interface{})
+	if !ok || len(coordinates) != 2 {
+		return fmt.Errorf("point must be an array of 2 numbers")
+	}
+	p.X, _ = coordinates[0].(float64)
+	p.Y, _ = coordinates[1].(float64)
+	return nil
+}
+
+// Decimal implements json.Marshaler and json.Unmarshaler.
+type Decimal struct {
+	value string
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.value), nil
+}
+
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	d.value = string(b)
+	return nil
+}
+
+// UUID implements encoding.TextMarshaler and encoding.TextUnmarshaler.
+type UUID [4]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%x", u[:])), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil || len(b) != len(u) {
+		return fmt.Errorf("invalid uuid %q", text)
+	}
+	copy(u[:], b)
+	return nil
+}
+
+// Status implements encoding.TextMarshaler and encoding.TextUnmarshaler.
+type Status int
+
+const (
+	StatusDraft Status = iota
+	StatusPublished
+)
+
+func (s Status) MarshalText() ([]byte, error) {
+	switch s {
+	case StatusDraft:
+		return []byte("draft"), nil
+	case StatusPublished:
+		return []byte("published"), nil
+	}
+	return nil, fmt.Errorf("unknown status %d", s)
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "draft":
+		*s = StatusDraft
+	case "published":
+		*s = StatusPublished
+	default:
+		return fmt.Errorf("unknown status %q", text)
+	}
+	return nil
+}
+
+type TestInterfaces struct {
+	ID        string   `jsonapi:"primary,test_interfaces"`
+	Location  Point    `jsonapi:"attribute,location"`
+	Price     Decimal  `jsonapi:"attribute,price"`
+	PricePtr  *Decimal `jsonapi:"attribute,price_ptr"`
+	Reference UUID     `jsonapi:"attribute,reference"`
+	Status    Status   `jsonapi:"attribute,status"`
+	StatusPtr *Status  `jsonapi:"meta,status_ptr"`
+}
+
+func TestMarshalInterfaces(t *testing.T) {
+	published := StatusPublished
+	test := TestInterfaces{
+		ID:        "someID",
+		Location:  Point{X: 1.5, Y: -2},
+		Price:     Decimal{value: "10.99"},
+		Reference: UUID{0xde, 0xad, 0xbe, 0xef},
+		Status:    StatusDraft,
+		StatusPtr: &published,
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_interfaces",
+		"attributes": {
+			"location": [
+				1.5,
+				-2
+			],
+			"price": 10.99,
+			"reference": "deadbeef",
+			"status": "draft"
+		},
+		"meta": {
+			"status_ptr": "published"
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+
+	// errors are annotated with the member
+	invalid := TestInterfaces{
+		ID:     "someID",
+		Status: Status(99),
+	}
+	invalidErrMsg := "type: test_interfaces, member: status, unknown status 99"
+	if _, err := Marshal(&invalid, nil); err == nil {
+		t.Errorf("expected error: %s, but got no error", invalidErrMsg)
+	} else if err.Error() != invalidErrMsg {
+		t.Errorf("expected error: %s, got: %s", invalidErrMsg, err.Error())
+	}
+}
+
+type TestAddress struct {
+	Street  string `jsonapi:"attribute,street"`
+	City    string `jsonapi:"attribute,city"`
+	Country string `jsonapi:"attribute,country,omitempty"`
+}
+
+type TestCoordinates struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+type TestLocation struct {
+	ID          string           `jsonapi:"primary,test_locations"`
+	Address     TestAddress      `jsonapi:"attribute,address"`
+	Billing     *TestAddress     `jsonapi:"attribute,billing"`
+	Shipping    *TestAddress     `jsonapi:"attribute,shipping"`
+	Coordinates TestCoordinates  `jsonapi:"attribute,coordinates"`
+	Source      *TestCoordinates `jsonapi:"meta,source"`
+}
+
+func TestMarshalNestedStruct(t *testing.T) {
+	test := TestLocation{
+		ID: "someID",
+		Address: TestAddress{
+			Street: "1 Main St",
+			City:   "Springfield",
+		},
+		Billing: &TestAddress{
+			Street:  "2 Side St",
+			City:    "Shelbyville",
+			Country: "US",
+		},
+		Coordinates: TestCoordinates{
+			Lat: 1.5,
+			Lng: -2.5,
+		},
+		Source: &TestCoordinates{},
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_locations",
+		"attributes": {
+			"address": {
+				"city": "Springfield",
+				"street": "1 Main St"
+			},
+			"billing": {
+				"city": "Shelbyville",
+				"country": "US",
+				"street": "2 Side St"
+			},
+			"coordinates": {
+				"lat": 1.5,
+				"lng": -2.5
+			}
+		},
+		"meta": {
+			"source": {
+				"lat": 0,
+				"lng": 0
+			}
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+}
+
+type TestMaps struct {
+	ID        string                    `jsonapi:"primary,test_maps"`
+	Labels    map[string]string         `jsonapi:"attribute,labels"`
+	Settings  map[string]interface{}    `jsonapi:"attribute,settings"`
+	Addresses map[Status]TestAddress    `jsonapi:"attribute,addresses"`
+	Counts    map[string]map[string]int `jsonapi:"meta,counts"`
+	Empty     map[string]string         `jsonapi:"attribute,empty,omitempty"`
+}
+
+func TestMarshalMaps(t *testing.T) {
+	test := TestMaps{
+		ID: "someID",
+		Labels: map[string]string{
+			"env":  "production",
+			"team": "core",
+		},
+		Settings: map[string]interface{}{
+			"enabled": true,
+			"limit":   10,
+			"theme": map[string]interface{}{
+				"color": "dark",
+			},
+		},
+		Addresses: map[Status]TestAddress{
+			StatusPublished: {Street: "1 Main St", City: "Springfield"},
+		},
+		Counts: map[string]map[string]int{
+			"views": {"today": 3},
+		},
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_maps",
+		"attributes": {
+			"addresses": {
+				"published": {
+					"city": "Springfield",
+					"street": "1 Main St"
+				}
+			},
+			"labels": {
+				"env": "production",
+				"team": "core"
+			},
+			"settings": {
+				"enabled": true,
+				"limit": 10,
+				"theme": {
+					"color": "dark"
+				}
+			}
+		},
+		"meta": {
+			"counts": {
+				"views": {
+					"today": 3
+				}
+			}
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+
+	// test integer keys round trip as decimal strings
+	type TestIntKeys struct {
+		ID     string           `jsonapi:"primary,test_int_keys"`
+		Scores map[int]string   `jsonapi:"attribute,scores"`
+		Ranks  map[uint8]string `jsonapi:"attribute,ranks"`
+	}
+	intKeys := TestIntKeys{
+		ID:     "someID",
+		Scores: map[int]string{-1: "minus one", 10: "ten"},
+		Ranks:  map[uint8]string{1: "first"},
+	}
+	expected = []byte(`{"data":{"id":"someID","type":"test_int_keys","attributes":{"ranks":{"1":"first"},"scores":{"-1":"minus one","10":"ten"}}},"jsonapi":{"version":"1.0"}}`)
+	got, err := Marshal(&intKeys, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if bytes.Compare(got, expected) != 0 {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+	}
+	gotIntKeys := TestIntKeys{}
+	if err := Unmarshal(got, &gotIntKeys); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(gotIntKeys, intKeys) {
+		t.Errorf("Expected: %+v, got: %+v", intKeys, gotIntKeys)
+	}
+	expectedError := `type: test_int_keys, pointer: /data/attributes/ranks, strconv.ParseUint: parsing "256": value out of range`
+	if err := Unmarshal([]byte(`{"data":{"id":"someID","type":"test_int_keys","attributes":{"ranks":{"256":"last"}}}}`), &TestIntKeys{}); err == nil || err.Error() != expectedError {
+		t.Errorf("Expected error: %s, got: %v", expectedError, err)
+	}
+
+	// test unsupported key type
+	type TestFloatKeys struct {
+		ID     string             `jsonapi:"primary,test_float_keys"`
+		Scores map[float64]string `jsonapi:"attribute,scores"`
+	}
+	expectedError = "type: test_float_keys, member: scores, map key type: float64, not supported"
+	if _, err := Marshal(&TestFloatKeys{ID: "someID", Scores: map[float64]string{1.5: "one and a half"}}, nil); err == nil || err.Error() != expectedError {
+		t.Errorf("Expected error: %s, got: %v", expectedError, err)
+	}
+}
+
+type TestBinding string
+
+type TestSlices struct {
+	ID        string        `jsonapi:"primary,test_slices"`
+	Floats    []float64     `jsonapi:"attribute,floats"`
+	Bools     []bool        `jsonapi:"attribute,bools"`
+	Int64s    []int64       `jsonapi:"attribute,int64s"`
+	Bindings  []TestBinding `jsonapi:"attribute,bindings"`
+	Statuses  []Status      `jsonapi:"attribute,statuses"`
+	Pointers  []*string     `jsonapi:"attribute,pointers"`
+	Addresses []TestAddress `jsonapi:"attribute,addresses"`
+	Matrix    [][]int       `jsonapi:"attribute,matrix"`
+	Pair      [2]int        `jsonapi:"attribute,pair"`
+	Bytes     []byte        `jsonapi:"attribute,bytes"`
+}
+
+func TestMarshalSlices(t *testing.T) {
+	hello := "hello"
+	test := TestSlices{
+		ID:        "someID",
+		Floats:    []float64{1.5, 2},
+		Bools:     []bool{true, false},
+		Int64s:    []int64{math.MaxInt64},
+		Bindings:  []TestBinding{"Hardcover", "Paperback"},
+		Statuses:  []Status{StatusDraft, StatusPublished},
+		Pointers:  []*string{&hello, nil},
+		Addresses: []TestAddress{{Street: "1 Main St", City: "Springfield"}},
+		Matrix:    [][]int{{1, 2}, {3}},
+		Pair:      [2]int{4, 5},
+		Bytes:     []byte("hi"),
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_slices",
+		"attributes": {
+			"addresses": [
+				{
+					"city": "Springfield",
+					"street": "1 Main St"
+				}
+			],
+			"bindings": [
+				"Hardcover",
+				"Paperback"
+			],
+			"bools": [
+				true,
+				false
+			],
+			"bytes": "aGk=",
+			"floats": [
+				1.5,
+				2
+			],
+			"int64s": [
+				9223372036854775807
+			],
+			"matrix": [
+				[
+					1,
+					2
+				],
+				[
+					3
+				]
+			],
+			"pair": [
+				4,
+				5
+			],
+			"pointers": [
+				"hello",
+				null
+			],
+			"statuses": [
+				"draft",
+				"published"
+			]
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+}
+
+func TestMarshalMissingID(t *testing.T) {
+	type TestMissingID struct {
+		ID    *int   `jsonapi:"primary,test_missing_ids"`
+		Title string `jsonapi:"attribute,title"`
+	}
+	test := TestMissingID{
+		Title: "New",
+	}
+	expectedError := "ID must be set"
+	if _, err := Marshal(&test, nil); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+
+	// test allowing missing ids
+	expected := []byte(`{
+	"data": {
+		"type": "test_missing_ids",
+		"attributes": {
+			"title": "New"
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{AllowMissingID: true, Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+
+	// test related resources without ids aren't mistaken for one another
+	type TestMissingIDComment struct {
+		ID   string `jsonapi:"primary,test_missing_id_comments"`
+		Body string `jsonapi:"attribute,body"`
+	}
+	type TestMissingIDArticle struct {
+		ID       string                  `jsonapi:"primary,test_missing_id_articles"`
+		Comments []*TestMissingIDComment `jsonapi:"relationship,comments"`
+	}
+	comment := &TestMissingIDComment{Body: "First"}
+	article := TestMissingIDArticle{
+		ID:       "1",
+		Comments: []*TestMissingIDComment{comment, {Body: "Second"}, comment},
+	}
+	got, err := Marshal(&article, &MarshalParams{AllowMissingID: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Document{}
+	if err := json.Unmarshal(got, &d); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Included) != 2 || d.Included[0].Attributes["body"] != "First" || d.Included[1].Attributes["body"] != "Second" {
+		t.Errorf("expected both comments to be included once, got: %s", string(got))
+	}
+}
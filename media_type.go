@@ -0,0 +1,122 @@
+package jsonapi
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// MediaType is the JSON:API media type, with the extension and profile URIs of its ext and profile
+// parameters.
+// See https://jsonapi.org/format/1.1/#media-type-parameter-rules.
+type MediaType struct {
+	Ext     []string
+	Profile []string
+}
+
+// ParseMediaType parses a JSON:API media type, e.g.: a Content-Type header value. Media types other
+// than JSON:API, and parameters other than ext and profile, are errors.
+func ParseMediaType(value string) (*MediaType, error) {
+	m, params, isJSONAPI, err := parseMediaType(value)
+	if err != nil {
+		return nil, err
+	}
+	if !isJSONAPI {
+		return nil, fmt.Errorf("media type: %s, not supported", value)
+	}
+	if len(params) > 0 {
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("media type parameter: %s, not supported", strings.Join(names, ", "))
+	}
+	return m, nil
+}
+
+// String returns the JSON:API media type with its ext and profile parameters, if any.
+func (m *MediaType) String() string {
+	params := map[string]string{}
+	if len(m.Ext) > 0 {
+		params["ext"] = strings.Join(m.Ext, " ")
+	}
+	if len(m.Profile) > 0 {
+		params["profile"] = strings.Join(m.Profile, " ")
+	}
+	return mime.FormatMediaType(ContentType, params)
+}
+
+// parseMediaType returns the ext and profile parameters of media type value, its other parameters
+// and whether it is the JSON:API media type.
+func parseMediaType(value string) (m *MediaType, params map[string]string, isJSONAPI bool, err error) {
+	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if mediaType != ContentType {
+		return nil, nil, false, err
+	}
+	m = &MediaType{
+		Ext:     strings.Fields(params["ext"]),
+		Profile: strings.Fields(params["profile"]),
+	}
+	delete(params, "ext")
+	delete(params, "profile")
+	return m, params, true, err
+}
+
+// RegisterExtension registers uri as a supported JSON:API extension, so requests using it aren't
+// rejected by ContentNegotiation and responses to them list it.
+// See https://jsonapi.org/format/1.1/#extensions.
+func RegisterExtension(uri string) {
+	defaultSerializer.RegisterExtension(uri)
+}
+
+// RegisterExtension registers uri as a supported JSON:API extension, so requests using it aren't
+// rejected by ContentNegotiation and responses to them list it.
+// See https://jsonapi.org/format/1.1/#extensions.
+func (s *Serializer) RegisterExtension(uri string) {
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+	s.extensions[uri] = true
+}
+
+// supportsExtensions reports whether every extension in uris is registered.
+func (s *Serializer) supportsExtensions(uris []string) bool {
+	s.extensionsMu.RLock()
+	defer s.extensionsMu.RUnlock()
+	for _, uri := range uris {
+		if !s.extensions[uri] {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterProfile registers uri as a supported JSON:API profile, so responses to requests using it
+// list it. Unregistered profiles are ignored.
+// See https://jsonapi.org/format/1.1/#profiles.
+func RegisterProfile(uri string) {
+	defaultSerializer.RegisterProfile(uri)
+}
+
+// RegisterProfile registers uri as a supported JSON:API profile, so responses to requests using it
+// list it. Unregistered profiles are ignored.
+// See https://jsonapi.org/format/1.1/#profiles.
+func (s *Serializer) RegisterProfile(uri string) {
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+	s.profiles[uri] = true
+}
+
+// supportedProfiles returns the registered profiles in uris.
+func (s *Serializer) supportedProfiles(uris []string) []string {
+	s.extensionsMu.RLock()
+	defer s.extensionsMu.RUnlock()
+	var profiles []string
+	for _, uri := range uris {
+		if s.profiles[uri] {
+			profiles = append(profiles, uri)
+		}
+	}
+	return profiles
+}
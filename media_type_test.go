@@ -0,0 +1,99 @@
+package jsonapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseMediaType(t *testing.T) {
+	valids := map[string]MediaType{
+		ContentType: {},
+		ContentType + `; ext="https://jsonapi.org/ext/atomic"`: {
+			Ext: []string{"https://jsonapi.org/ext/atomic"},
+		},
+		ContentType + `;ext="https://a.com/ext https://b.com/ext"; profile="https://c.com/profile"`: {
+			Ext:     []string{"https://a.com/ext", "https://b.com/ext"},
+			Profile: []string{"https://c.com/profile"},
+		},
+	}
+	for value, expected := range valids {
+		m, err := ParseMediaType(value)
+		if err != nil {
+			t.Errorf("%s: %s", value, err.Error())
+			continue
+		}
+		expected.Ext = append([]string{}, expected.Ext...)
+		expected.Profile = append([]string{}, expected.Profile...)
+		if !reflect.DeepEqual(*m, expected) {
+			t.Errorf("%s: expected: %+v, got: %+v", value, expected, *m)
+		}
+	}
+
+	invalids := map[string]string{
+		"application/json":                        "media type: application/json, not supported",
+		ContentType + "; charset=utf-8":           "media type parameter: charset, not supported",
+		ContentType + "; q=1; charset=utf-8; a=b": "media type parameter: a, charset, q, not supported",
+	}
+	for value, expectedError := range invalids {
+		if _, err := ParseMediaType(value); err == nil || err.Error() != expectedError {
+			t.Errorf("expected error: %s, got: %v", expectedError, err)
+		}
+	}
+
+	// test formatting
+	m := MediaType{
+		Ext:     []string{"https://a.com/ext", "https://b.com/ext"},
+		Profile: []string{"https://c.com/profile"},
+	}
+	expected := `application/vnd.api+json; ext="https://a.com/ext https://b.com/ext"; profile="https://c.com/profile"`
+	if m.String() != expected {
+		t.Errorf("expected media type: %s, got: %s", expected, m.String())
+	}
+}
+
+func TestRespondMediaType(t *testing.T) {
+	type Car struct {
+		VIN string `jsonapi:"primary,cars"`
+	}
+	s := NewSerializer()
+	s.RegisterExtension("https://jsonapi.org/ext/atomic")
+	s.RegisterProfile("https://example.com/profile")
+
+	r := httptest.NewRequest("GET", "http://example.com/foo?pretty", nil)
+	r.Header.Set("Accept", ContentType+`; ext="https://jsonapi.org/ext/atomic"; profile="https://example.com/unknown https://example.com/profile"`)
+	w := httptest.NewRecorder()
+	if err := s.Respond(w, r, http.StatusOK, &Car{VIN: "5YJSA1DG9DFP14705"}, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedContentType := ContentType + `; ext="https://jsonapi.org/ext/atomic"; profile="https://example.com/profile"`
+	if res.Header.Get("Content-Type") != expectedContentType {
+		t.Errorf("expected content-type header: %s, got: %s", expectedContentType, res.Header.Get("Content-Type"))
+	}
+	expectedBody := []byte(`{
+	"data": {
+		"id": "5YJSA1DG9DFP14705",
+		"type": "cars"
+	},
+	"jsonapi": {
+		"version": "1.1",
+		"ext": [
+			"https://jsonapi.org/ext/atomic"
+		],
+		"profile": [
+			"https://example.com/profile"
+		]
+	}
+}`)
+	if bytes.Compare(body, expectedBody) != 0 {
+		t.Errorf("expected body: %s, got: %s", string(expectedBody), string(body))
+	}
+}
@@ -34,21 +34,64 @@ func newMemberType(s string) (memberType, error) {
 	}
 }
 
-func getMember(field reflect.StructField) (memberType, string, error) {
+func getMember(field reflect.StructField, tagKey string) (memberType, string, tagOptions, error) {
 	tag, ok := field.Tag.Lookup(tagKey)
 	if !ok {
-		return "", "", fmt.Errorf("tag: %s, not specified", tagKey)
+		return "", "", nil, fmt.Errorf("tag: %s, not specified", tagKey)
 	}
 	if tag == "" {
-		return "", "", fmt.Errorf("tag: %s, was empty", tagKey)
+		return "", "", nil, fmt.Errorf("tag: %s, was empty", tagKey)
 	}
 	tagParts := strings.Split(tag, ",")
-	if len(tagParts) != 2 {
-		return "", "", fmt.Errorf("tag: %s, was not formatted properly", tagKey)
+	if len(tagParts) < 2 {
+		return "", "", nil, fmt.Errorf("tag: %s, was not formatted properly", tagKey)
 	}
 	memberType, err := newMemberType(tagParts[0])
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
-	return memberType, tagParts[1], nil
+	return memberType, tagParts[1], tagOptions(tagParts[2:]), nil
+}
+
+// tagOptions are the comma-separated options following the member name in a tag, e.g.:
+// `jsonapi:"attribute,name,omitempty"`.
+type tagOptions []string
+
+// Contains reports whether option name is set.
+func (o tagOptions) Contains(name string) bool {
+	for _, option := range o {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the value of a key=value option.
+func (o tagOptions) Get(key string) (string, bool) {
+	for _, option := range o {
+		if strings.HasPrefix(option, key+"=") {
+			return strings.TrimPrefix(option, key+"="), true
+		}
+	}
+	return "", false
+}
+
+// isEmptyValue reports whether v is empty following the encoding/json omitempty rules.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
 }
@@ -32,11 +32,13 @@ func TestNewMemberType(t *testing.T) {
 }
 
 func TestGetMember(t *testing.T) {
+	tagKey := defaultSerializer.tagKey
 	type GetMemterTest struct {
 		ID        string `jsonapi:"primary,corrects"`
 		Correct   string `jsonapi:"attribute,correct"`
+		OmitEmpty string `jsonapi:"attribute,omit_empty,omitempty"`
 		Incorrect string `jsonapi:"foo,empty"`
-		Malformed string `jsonapi:"foo,bar,malformed"`
+		Malformed string `jsonapi:"attribute"`
 		Empty     string `jsonapi:""`
 		NoTag     string
 	}
@@ -50,35 +52,49 @@ func TestGetMember(t *testing.T) {
 	if c, ok := reflect.TypeOf(test).FieldByName("Correct"); !ok {
 		t.Fatal("not ok")
 	} else {
-		if _, _, err := getMember(c); err != nil {
+		if _, _, _, err := getMember(c, tagKey); err != nil {
 			t.Errorf("got unexpected error: %s, for correct member: %s", err, "attribute")
 		}
 	}
+	if o, ok := reflect.TypeOf(test).FieldByName("OmitEmpty"); !ok {
+		t.Fatal("not ok")
+	} else {
+		memberType, memberName, options, err := getMember(o, tagKey)
+		switch {
+		case err != nil:
+			t.Errorf("got unexpected error: %s, for member with options: %s", err, "omitempty")
+		case memberType != memberTypeAttribute || memberName != "omit_empty":
+			t.Errorf("expected member: %s %s, got: %s %s", memberTypeAttribute, "omit_empty", memberType, memberName)
+		case !options.Contains("omitempty"):
+			t.Errorf("expected options: %v, to contain: %s", options, "omitempty")
+		}
+	}
 	if i, ok := reflect.TypeOf(test).FieldByName("Incorrect"); !ok {
 		t.Fatal("not ok")
 	} else {
-		if _, _, err := getMember(i); err == nil {
+		if _, _, _, err := getMember(i, tagKey); err == nil {
 			t.Errorf("expected incorrect member: %s, to error out", "foo")
 		}
 	}
 	if e, ok := reflect.TypeOf(test).FieldByName("Malformed"); !ok {
 		t.Fatal("not ok")
 	} else {
-		if _, _, err := getMember(e); err == nil {
-			t.Errorf("expected malformed tag error: %s, but got no error", fmt.Errorf("tag: %s, was not formatted properly", tagKey))
+		expected := fmt.Errorf("tag: %s, was not formatted properly", tagKey)
+		if _, _, _, err := getMember(e, tagKey); err == nil || err.Error() != expected.Error() {
+			t.Errorf("expected malformed tag error: %s, but got: %v", expected, err)
 		}
 	}
 	if e, ok := reflect.TypeOf(test).FieldByName("Empty"); !ok {
 		t.Fatal("not ok")
 	} else {
-		if _, _, err := getMember(e); err == nil {
+		if _, _, _, err := getMember(e, tagKey); err == nil {
 			t.Errorf("expected empty tag error: %s, but got no error", fmt.Errorf("tag: %s, not specified", tagKey))
 		}
 	}
 	if e, ok := reflect.TypeOf(test).FieldByName("NoTag"); !ok {
 		t.Fatal("not ok")
 	} else {
-		if _, _, err := getMember(e); err == nil {
+		if _, _, _, err := getMember(e, tagKey); err == nil {
 			t.Errorf("expected tag missing error: %s, but got no error", fmt.Errorf("tag: %s, not specified", tagKey))
 		}
 	}
@@ -0,0 +1,123 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentNegotiation returns a handler enforcing the JSON:API content negotiation rules before
+// calling next. Requests with a JSON:API Content-Type modified with media type parameters other
+// than ext and profile, or using unregistered extensions, get a 415 Unsupported Media Type error.
+// Requests whose Accept header only has such JSON:API media types get a 406 Not Acceptable error.
+// See https://jsonapi.org/format/1.1/#content-negotiation-servers.
+func ContentNegotiation(next http.Handler) http.Handler {
+	return defaultSerializer.ContentNegotiation(next)
+}
+
+// ContentNegotiation returns a handler enforcing the JSON:API content negotiation rules before
+// calling next. Requests with a JSON:API Content-Type modified with media type parameters other
+// than ext and profile, or using unregistered extensions, get a 415 Unsupported Media Type error.
+// Requests whose Accept header only has such JSON:API media types get a 406 Not Acceptable error.
+// See https://jsonapi.org/format/1.1/#content-negotiation-servers.
+func (s *Serializer) ContentNegotiation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.checkContentType(r); err != nil {
+			s.RespondError(w, r, http.StatusUnsupportedMediaType, nil, *err)
+			return
+		}
+		if _, err := s.acceptedMediaType(r); err != nil {
+			s.RespondError(w, r, http.StatusNotAcceptable, nil, *err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkContentType returns the 415 Unsupported Media Type error object of a request with a JSON:API
+// Content-Type that isn't supported.
+func (s *Serializer) checkContentType(r *http.Request) *Error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	m, params, isJSONAPI, err := parseMediaType(contentType)
+	if !isJSONAPI {
+		return nil
+	}
+	var detail string
+	switch {
+	case err != nil || len(params) > 0:
+		detail = fmt.Sprintf("media type '%s' must not have parameters other than ext and profile", contentType)
+	case !s.supportsExtensions(m.Ext):
+		detail = fmt.Sprintf("media type '%s' has unsupported extensions", contentType)
+	default:
+		return nil
+	}
+	return &Error{
+		Status: strconv.Itoa(http.StatusUnsupportedMediaType),
+		Title:  http.StatusText(http.StatusUnsupportedMediaType),
+		Detail: detail,
+		Source: map[string]string{
+			"header": "Content-Type",
+		},
+	}
+}
+
+// acceptedMediaType returns the first supported JSON:API media type in the Accept header of r, or
+// nil if it has none. The error is the 406 Not Acceptable error object of a request whose Accept
+// header has JSON:API media types, none of them supported.
+func (s *Serializer) acceptedMediaType(r *http.Request) (*MediaType, *Error) {
+	accept := strings.Join(r.Header["Accept"], ",")
+	found := false
+	for _, mediaRange := range strings.Split(accept, ",") {
+		m, params, isJSONAPI, err := parseMediaType(mediaRange)
+		if !isJSONAPI {
+			continue
+		}
+		// the quality factor of media ranges isn't a media type parameter
+		delete(params, "q")
+		if err == nil && len(params) == 0 && s.supportsExtensions(m.Ext) {
+			return m, nil
+		}
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return nil, &Error{
+		Status: strconv.Itoa(http.StatusNotAcceptable),
+		Title:  http.StatusText(http.StatusNotAcceptable),
+		Detail: fmt.Sprintf("media type '%s' must be accepted without parameters other than ext and profile, nor unsupported extensions", ContentType),
+		Source: map[string]string{
+			"header": "Accept",
+		},
+	}
+}
+
+// responseMediaType returns the JSON:API media type of the response to r: the first supported one
+// it accepts or, when it doesn't accept any, the one of its body. Only registered profiles are
+// kept, since the others aren't applied.
+func (s *Serializer) responseMediaType(r *http.Request) *MediaType {
+	m := s.requestedMediaType(r)
+	m.Profile = s.supportedProfiles(m.Profile)
+	return m
+}
+
+// requestedMediaType returns the first supported JSON:API media type r accepts or, when it doesn't
+// accept any, the one of its body.
+func (s *Serializer) requestedMediaType(r *http.Request) *MediaType {
+	if r == nil {
+		return &MediaType{}
+	}
+	if m, _ := s.acceptedMediaType(r); m != nil {
+		return m
+	}
+	if s.checkContentType(r) == nil {
+		if m, err := ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+			return m
+		}
+	}
+	return &MediaType{}
+}
@@ -0,0 +1,91 @@
+package jsonapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	type negotiationTest struct {
+		ContentType        string
+		Accept             []string
+		ExpectedStatusCode int
+	}
+
+	tests := []negotiationTest{
+		{ExpectedStatusCode: http.StatusOK},
+		{ContentType: ContentType, Accept: []string{ContentType}, ExpectedStatusCode: http.StatusOK},
+		{ContentType: "application/json; charset=utf-8", Accept: []string{"application/json"}, ExpectedStatusCode: http.StatusOK},
+		{ContentType: ContentType + "; charset=utf-8", ExpectedStatusCode: http.StatusUnsupportedMediaType},
+		{ContentType: ContentType + "; charset", ExpectedStatusCode: http.StatusUnsupportedMediaType},
+		{Accept: []string{ContentType + "; charset=utf-8"}, ExpectedStatusCode: http.StatusNotAcceptable},
+		{Accept: []string{ContentType + "; charset=utf-8", ContentType + "; version=1"}, ExpectedStatusCode: http.StatusNotAcceptable},
+		{Accept: []string{ContentType + "; charset=utf-8, " + ContentType}, ExpectedStatusCode: http.StatusOK},
+		{Accept: []string{ContentType + "; charset=utf-8", "*/*"}, ExpectedStatusCode: http.StatusNotAcceptable},
+		{Accept: []string{ContentType + "; q=0.5"}, ExpectedStatusCode: http.StatusOK},
+		{Accept: []string{"text/html, */*"}, ExpectedStatusCode: http.StatusOK},
+		{ContentType: ContentType + `; profile="https://example.com/profiles/timestamps"`, ExpectedStatusCode: http.StatusOK},
+		{ContentType: ContentType + `; ext="https://jsonapi.org/ext/atomic"`, ExpectedStatusCode: http.StatusOK},
+		{ContentType: ContentType + `; ext="https://jsonapi.org/ext/atomic https://example.com/ext/bulk"`, ExpectedStatusCode: http.StatusUnsupportedMediaType},
+		{Accept: []string{ContentType + `; ext="https://example.com/ext/bulk"`}, ExpectedStatusCode: http.StatusNotAcceptable},
+		{Accept: []string{ContentType + `; ext="https://example.com/ext/bulk", ` + ContentType + `; ext="https://jsonapi.org/ext/atomic"`}, ExpectedStatusCode: http.StatusOK},
+	}
+
+	s := NewSerializer()
+	s.SetFormat(PrettyFormat)
+	s.RegisterExtension("https://jsonapi.org/ext/atomic")
+
+	handler := s.ContentNegotiation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, nt := range tests {
+		r := httptest.NewRequest("POST", "http://example.com/foo", nil)
+		if nt.ContentType != "" {
+			r.Header.Set("Content-Type", nt.ContentType)
+		}
+		for _, accept := range nt.Accept {
+			r.Header.Add("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		res := w.Result()
+		if res.StatusCode != nt.ExpectedStatusCode {
+			t.Errorf("Content-Type: %q, Accept: %q, expected status code: %d, got: %d", nt.ContentType, nt.Accept, nt.ExpectedStatusCode, res.StatusCode)
+		}
+	}
+
+	// test rejections are JSON:API error documents
+	r := httptest.NewRequest("POST", "http://example.com/foo", nil)
+	r.Header.Set("Content-Type", ContentType+"; charset=utf-8")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedBody := []byte(`{
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"errors": [
+		{
+			"status": "415",
+			"title": "Unsupported Media Type",
+			"detail": "media type 'application/vnd.api+json; charset=utf-8' must not have parameters other than ext and profile",
+			"source": {
+				"header": "Content-Type"
+			}
+		}
+	]
+}`)
+	if res.Header.Get("Content-Type") != ContentType {
+		t.Errorf("expected content-type header: %s, got: %s", ContentType, res.Header.Get("Content-Type"))
+	}
+	if bytes.Compare(body, expectedBody) != 0 {
+		t.Errorf("expected body: %s, got: %s", string(expectedBody), string(body))
+	}
+}
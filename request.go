@@ -0,0 +1,100 @@
+package jsonapi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// DefaultMaxBodySize is the default maximum size in bytes of the request bodies read by
+// DecodeRequest.
+const DefaultMaxBodySize = 1 << 20
+
+// DecodeRequest reads the JSON:API document in the body of request r and stores the result in the
+// value pointed to by v. Errors can be turned in to error objects with NewErrors: bodies without
+// a supported JSON:API Content-Type get a 415 Unsupported Media Type error, bodies larger than the
+// maximum body size a 413 Request Entity Too Large error, and invalid documents 400 Bad Request,
+// 403 Forbidden, 409 Conflict or 422 Unprocessable Entity errors.
+func DecodeRequest(r *http.Request, v interface{}) error {
+	return defaultSerializer.DecodeRequest(r, v)
+}
+
+// DecodeRequest reads the JSON:API document in the body of request r and stores the result in the
+// value pointed to by v. Errors can be turned in to error objects with NewErrors: bodies without
+// a supported JSON:API Content-Type get a 415 Unsupported Media Type error, bodies larger than the
+// maximum body size a 413 Request Entity Too Large error, and invalid documents 400 Bad Request,
+// 403 Forbidden, 409 Conflict or 422 Unprocessable Entity errors.
+func (s *Serializer) DecodeRequest(r *http.Request, v interface{}) error {
+	return s.DecodeRequestWithParams(r, v, nil)
+}
+
+// DecodeRequestWithParams reads the JSON:API document in the body of request r and stores the
+// result in the value pointed to by v, as configured by p.
+func DecodeRequestWithParams(r *http.Request, v interface{}, p *UnmarshalParams) error {
+	return defaultSerializer.DecodeRequestWithParams(r, v, p)
+}
+
+// DecodeRequestWithParams reads the JSON:API document in the body of request r and stores the
+// result in the value pointed to by v, as configured by p.
+func (s *Serializer) DecodeRequestWithParams(r *http.Request, v interface{}, p *UnmarshalParams) error {
+	if err := s.checkRequestContentType(r); err != nil {
+		return err
+	}
+	data, err := s.readBody(r)
+	if err != nil {
+		return err
+	}
+	return s.UnmarshalWithParams(data, v, p)
+}
+
+// SetMaxBodySize sets the maximum size in bytes of the request bodies read by DecodeRequest.
+func SetMaxBodySize(size int64) {
+	defaultSerializer.SetMaxBodySize(size)
+}
+
+// SetMaxBodySize sets the maximum size in bytes of the request bodies read by DecodeRequest.
+func (s *Serializer) SetMaxBodySize(size int64) {
+	s.maxBodySize = size
+}
+
+// checkRequestContentType returns the 415 Unsupported Media Type error object of a request whose
+// Content-Type isn't a supported JSON:API media type.
+func (s *Serializer) checkRequestContentType(r *http.Request) *Error {
+	if err := s.checkContentType(r); err != nil {
+		return err
+	}
+	contentType := r.Header.Get("Content-Type")
+	if _, _, isJSONAPI, _ := parseMediaType(contentType); isJSONAPI {
+		return nil
+	}
+	return &Error{
+		Status: strconv.Itoa(http.StatusUnsupportedMediaType),
+		Title:  http.StatusText(http.StatusUnsupportedMediaType),
+		Detail: fmt.Sprintf("media type '%s' must be '%s'", contentType, ContentType),
+		Source: map[string]string{
+			"header": "Content-Type",
+		},
+	}
+}
+
+// readBody reads the body of r, failing with a 413 Request Entity Too Large error object when it
+// is larger than the maximum body size.
+func (s *Serializer) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, s.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxBodySize {
+		return nil, &Error{
+			Status: strconv.Itoa(http.StatusRequestEntityTooLarge),
+			Title:  http.StatusText(http.StatusRequestEntityTooLarge),
+			Detail: fmt.Sprintf("request body must not be larger than %d bytes", s.maxBodySize),
+		}
+	}
+	return data, nil
+}
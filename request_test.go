@@ -0,0 +1,133 @@
+package jsonapi
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	type Car struct {
+		VIN  string `jsonapi:"primary,cars"`
+		Make string `jsonapi:"attribute,make"`
+	}
+
+	s := NewSerializer()
+	s.SetMaxBodySize(128)
+
+	type decodeRequestTest struct {
+		Body           string
+		ContentType    string
+		Params         *UnmarshalParams
+		Expected       Car
+		ExpectedErrors []Error
+	}
+	tests := map[string]decodeRequestTest{
+		"valid": {
+			Body:        `{"data": {"id": "5YJSA1DG9DFP14705", "type": "cars", "attributes": {"make": "Tesla"}}}`,
+			ContentType: ContentType,
+			Expected:    Car{VIN: "5YJSA1DG9DFP14705", Make: "Tesla"},
+		},
+		"missing content type": {
+			Body: `{"data": {"id": "5YJSA1DG9DFP14705", "type": "cars"}}`,
+			ExpectedErrors: []Error{{
+				Status: "415",
+				Title:  "Unsupported Media Type",
+				Detail: "media type '' must be 'application/vnd.api+json'",
+				Source: map[string]string{"header": "Content-Type"},
+			}},
+		},
+		"json content type": {
+			Body:        `{"data": {"id": "5YJSA1DG9DFP14705", "type": "cars"}}`,
+			ContentType: "application/json",
+			ExpectedErrors: []Error{{
+				Status: "415",
+				Title:  "Unsupported Media Type",
+				Detail: "media type 'application/json' must be 'application/vnd.api+json'",
+				Source: map[string]string{"header": "Content-Type"},
+			}},
+		},
+		"content type parameters": {
+			Body:        `{"data": {"id": "5YJSA1DG9DFP14705", "type": "cars"}}`,
+			ContentType: ContentType + "; charset=utf-8",
+			ExpectedErrors: []Error{{
+				Status: "415",
+				Title:  "Unsupported Media Type",
+				Detail: "media type 'application/vnd.api+json; charset=utf-8' must not have parameters other than ext and profile",
+				Source: map[string]string{"header": "Content-Type"},
+			}},
+		},
+		"body too large": {
+			Body:        `{"data": {"id": "5YJSA1DG9DFP14705", "type": "cars", "attributes": {"make": "` + strings.Repeat("a", 128) + `"}}}`,
+			ContentType: ContentType,
+			ExpectedErrors: []Error{{
+				Status: "413",
+				Title:  "Request Entity Too Large",
+				Detail: "request body must not be larger than 128 bytes",
+			}},
+		},
+		"malformed document": {
+			Body:        `{"data": `,
+			ContentType: ContentType,
+			ExpectedErrors: []Error{{
+				Status: "400",
+				Title:  "Bad Request",
+				Detail: "unexpected end of JSON input",
+			}},
+		},
+		"type conflict": {
+			Body:        `{"data": {"id": "5YJSA1DG9DFP14705", "type": "trucks"}}`,
+			ContentType: ContentType,
+			ExpectedErrors: []Error{{
+				Status: "409",
+				Title:  "Resource type conflict",
+				Detail: "resource type 'trucks' does not match 'cars'",
+				Source: map[string]string{"pointer": "/data/type"},
+			}},
+		},
+		"wrong types": {
+			Body:        `{"data": {"id": "5YJSA1DG9DFP14705", "type": "cars", "attributes": {"make": 12, "model": "S"}}}`,
+			ContentType: ContentType,
+			Params:      &UnmarshalParams{Strict: true, AllErrors: true},
+			ExpectedErrors: []Error{
+				{
+					Status: "422",
+					Title:  "Unprocessable Entity",
+					Detail: "invalid value for field string, expected string, got number 12",
+					Source: map[string]string{"pointer": "/data/attributes/make"},
+				},
+				{
+					Status: "400",
+					Title:  "Bad Request",
+					Detail: "attribute: model, not found",
+					Source: map[string]string{"pointer": "/data/attributes/model"},
+				},
+			},
+		},
+	}
+	for name, test := range tests {
+		r := httptest.NewRequest("POST", "http://example.com/cars", strings.NewReader(test.Body))
+		if test.ContentType != "" {
+			r.Header.Set("Content-Type", test.ContentType)
+		}
+		car := Car{}
+		err := s.DecodeRequestWithParams(r, &car, test.Params)
+		if test.ExpectedErrors == nil {
+			if err != nil {
+				t.Errorf("%s: expected no error, got: %s", name, err.Error())
+			}
+			if car != test.Expected {
+				t.Errorf("%s: expected: %+v, got: %+v", name, test.Expected, car)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected error, got no error", name)
+			continue
+		}
+		if got := NewErrors(err); !reflect.DeepEqual(got, test.ExpectedErrors) {
+			t.Errorf("%s: expected errors: %+v, got: %+v", name, test.ExpectedErrors, got)
+		}
+	}
+}
@@ -27,18 +27,13 @@ func NewResource() *Resource {
 	}
 }
 
-// SetIDAndType sets the id and type of a JSON:API resource object.
+// SetIDAndType sets the id and type of a JSON:API resource object. See formatID for the supported
+// id types.
 // TODO warn or error out when ID isn't plural?
 func (r *Resource) SetIDAndType(idValue reflect.Value, resourceType string) error {
-	kind := idValue.Kind()
-	id := ""
-	switch kind {
-	case reflect.String:
-		id, _ = idValue.Interface().(string)
-	case reflect.Int:
-		id = fmt.Sprintf("%d", idValue.Interface())
-	default:
-		return fmt.Errorf("ID must be a string or int, got %s", kind)
+	id, err := formatID(idValue)
+	if err != nil {
+		return err
 	}
 	if id == "" {
 		return fmt.Errorf("ID must be set")
@@ -60,3 +55,16 @@ func (r *Resource) SetLinks(linksValue reflect.Value) error {
 	r.Links = links
 	return nil
 }
+
+// resourceKey uniquely identifies a resource object within a document.
+type resourceKey struct {
+	Type string
+	ID   string
+}
+
+func (r *Resource) key() resourceKey {
+	return resourceKey{
+		Type: r.Type,
+		ID:   r.ID,
+	}
+}
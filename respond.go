@@ -2,28 +2,82 @@ package jsonapi
 
 import (
 	"net/http"
+	"strconv"
 )
 
 // Respond encodes v in to a JSON:API object and writes it to the body of response w. It also sets
 // statusCode as the response status code.
 func Respond(w http.ResponseWriter, r *http.Request, statusCode int, v interface{}, p *MarshalParams) error {
-	body, err := Marshal(v, p)
-	if err != nil {
-		return err
-	}
-	return respond(w, r, statusCode, body)
+	return defaultSerializer.Respond(w, r, statusCode, v, p)
+}
+
+// Respond encodes v in to a JSON:API object and writes it to the body of response w. It also sets
+// statusCode as the response status code. The extensions and profiles of the media type negotiated
+// with r are set in the Content-Type header and the jsonapi object. Unless p sets a format, the
+// body is pretty-printed when r has a pretty query parameter, e.g.: /articles?pretty. The body is
+// streamed as an Encoder does: errors marshaling the first primary data resource are returned
+// before anything is written, but errors on later ones leave the status code sent and the body
+// truncated.
+func (s *Serializer) Respond(w http.ResponseWriter, r *http.Request, statusCode int, v interface{}, p *MarshalParams) error {
+	m := s.responseMediaType(r)
+	rp := responseParams(r, p, m)
+	return s.NewEncoder(w).encode(v, rp, s.formatOf(rp), func() {
+		w.Header().Set("Content-Type", m.String())
+		w.WriteHeader(statusCode)
+	})
 }
 
 // RespondError encodes v in to a JSON:API error object and writes it to the body of response w. It
 // also sets statusCode as the response status code.
 func RespondError(w http.ResponseWriter, r *http.Request, statusCode int, p *MarshalParams, errs ...Error) error {
+	return defaultSerializer.RespondError(w, r, statusCode, p, errs...)
+}
+
+// RespondError encodes v in to a JSON:API error object and writes it to the body of response w. It
+// also sets statusCode as the response status code.
+func (s *Serializer) RespondError(w http.ResponseWriter, r *http.Request, statusCode int, p *MarshalParams, errs ...Error) error {
+	m := s.responseMediaType(r)
+	rp := responseParams(r, p, m)
 	// TODO figure out how to trigger this error for test coverage
-	body, _ := MarshalErrors(p, errs...)
-	return respond(w, r, statusCode, body)
+	body, _ := s.encodeJSON(newErrorsDocument(rp, errs), s.formatOf(rp))
+	return respond(w, m, statusCode, body)
+}
+
+// responseParams returns a copy of p listing the extensions and profiles of m, unless p already
+// lists some, and pretty-printing the response when r asks for it, unless p sets a format.
+func responseParams(r *http.Request, p *MarshalParams, m *MediaType) *MarshalParams {
+	mp := MarshalParams{}
+	if p != nil {
+		mp = *p
+	}
+	if len(mp.Ext) == 0 && len(mp.Profile) == 0 {
+		mp.Ext = m.Ext
+		mp.Profile = m.Profile
+	}
+	if mp.Format == DefaultFormat && wantsPretty(r) {
+		mp.Format = PrettyFormat
+	}
+	return &mp
+}
+
+// wantsPretty reports whether r has a pretty query parameter that is empty or true.
+func wantsPretty(r *http.Request) bool {
+	if r == nil || r.URL == nil {
+		return false
+	}
+	values, ok := r.URL.Query()["pretty"]
+	if !ok {
+		return false
+	}
+	if values[0] == "" {
+		return true
+	}
+	pretty, err := strconv.ParseBool(values[0])
+	return err == nil && pretty
 }
 
-func respond(w http.ResponseWriter, r *http.Request, statusCode int, body []byte) (err error) {
-	w.Header().Set("Content-Type", ContentType)
+func respond(w http.ResponseWriter, m *MediaType, statusCode int, body []byte) (err error) {
+	w.Header().Set("Content-Type", m.String())
 	w.WriteHeader(statusCode)
 	// TODO figure out how to trigger this error for test coverage
 	_, err = w.Write(body)
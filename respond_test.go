@@ -2,9 +2,11 @@ package jsonapi
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -45,7 +47,7 @@ func TestRespond(t *testing.T) {
 					Make:  "Honda",
 					Model: "CR-V",
 				}
-				if err := Respond(w, r, http.StatusOK, &car, nil); err != nil {
+				if err := Respond(w, r, http.StatusOK, &car, &MarshalParams{Format: PrettyFormat}); err != nil {
 					t.Errorf("expected no error, got: %s", err.Error())
 				}
 			}),
@@ -57,7 +59,7 @@ func TestRespond(t *testing.T) {
 					Make:  "Honda",
 					Model: "CR-V",
 				}
-				if err := Respond(w, r, http.StatusOK, &car, nil); err != nil {
+				if err := Respond(w, r, http.StatusOK, &car, &MarshalParams{Format: PrettyFormat}); err != nil {
 					if err.Error() != "ID must be set" {
 						t.Errorf("expected error: %s, got: %s", "ID must be set", err.Error())
 					}
@@ -110,7 +112,7 @@ func TestRespondError(t *testing.T) {
 			ExpectedContentType: ContentType,
 			ExpectedStatusCode:  http.StatusNotFound,
 			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if err := RespondError(w, r, http.StatusNotFound, nil, Error{Title: "not_found"}); err != nil {
+				if err := RespondError(w, r, http.StatusNotFound, &MarshalParams{Format: PrettyFormat}, Error{Title: "not_found"}); err != nil {
 					t.Errorf("expected no error, got: %s", err.Error())
 				}
 			}),
@@ -129,7 +131,7 @@ func TestRespondError(t *testing.T) {
 			ExpectedContentType: ContentType,
 			ExpectedStatusCode:  http.StatusInternalServerError,
 			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if err := RespondError(w, r, http.StatusInternalServerError, nil, Error{Title: "internal_server_error"}); err != nil {
+				if err := RespondError(w, r, http.StatusInternalServerError, &MarshalParams{Format: PrettyFormat}, Error{Title: "internal_server_error"}); err != nil {
 					t.Errorf("expected no error, got: %s", err.Error())
 				}
 			}),
@@ -152,7 +154,7 @@ func TestRespondError(t *testing.T) {
 			ExpectedContentType: ContentType,
 			ExpectedStatusCode:  http.StatusInternalServerError,
 			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if err := RespondError(w, r, http.StatusInternalServerError, nil, Error{Title: "internal_server_error"}, Error{ID: "BAD_REQUEST", Title: "bad_request"}); err != nil {
+				if err := RespondError(w, r, http.StatusInternalServerError, &MarshalParams{Format: PrettyFormat}, Error{Title: "internal_server_error"}, Error{ID: "BAD_REQUEST", Title: "bad_request"}); err != nil {
 					t.Errorf("expected no error, got: %s", err.Error())
 				}
 			}),
@@ -179,3 +181,103 @@ func TestRespondError(t *testing.T) {
 	}
 
 }
+
+func TestRespondFormat(t *testing.T) {
+	type Car struct {
+		VIN string `jsonapi:"primary,cars"`
+	}
+	car := Car{VIN: "5YJSA1DG9DFP14705"}
+	compact := `{"data":{"id":"5YJSA1DG9DFP14705","type":"cars"},"jsonapi":{"version":"1.0"}}`
+	pretty := `{
+	"data": {
+		"id": "5YJSA1DG9DFP14705",
+		"type": "cars"
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`
+	compactErrors := `{"jsonapi":{"version":"1.0"},"errors":[{"status":"404"}]}`
+	prettyErrors := `{
+	"jsonapi": {
+		"version": "1.0"
+	},
+	"errors": [
+		{
+			"status": "404"
+		}
+	]
+}`
+
+	s := NewSerializer()
+	type formatTest struct {
+		url            string
+		params         *MarshalParams
+		expected       string
+		expectedErrors string
+	}
+	tests := []formatTest{
+		{url: "/cars/1", expected: compact, expectedErrors: compactErrors},
+		{url: "/cars/1?pretty", expected: pretty, expectedErrors: prettyErrors},
+		{url: "/cars/1?pretty=true", expected: pretty, expectedErrors: prettyErrors},
+		{url: "/cars/1?pretty=false", expected: compact, expectedErrors: compactErrors},
+		{url: "/cars/1?pretty=maybe", expected: compact, expectedErrors: compactErrors},
+		{url: "/cars/1", params: &MarshalParams{Format: PrettyFormat}, expected: pretty, expectedErrors: prettyErrors},
+		{url: "/cars/1?pretty", params: &MarshalParams{Format: CompactFormat}, expected: compact, expectedErrors: compactErrors},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", test.url, nil)
+		w := httptest.NewRecorder()
+		if err := s.Respond(w, r, http.StatusOK, &car, test.params); err != nil {
+			t.Fatal(err.Error())
+		}
+		if body := w.Body.String(); body != test.expected {
+			t.Errorf("%s: expected body: %s, got: %s", test.url, test.expected, body)
+		}
+
+		w = httptest.NewRecorder()
+		if err := s.RespondError(w, r, http.StatusNotFound, test.params, Error{Status: "404"}); err != nil {
+			t.Fatal(err.Error())
+		}
+		if body := w.Body.String(); body != test.expectedErrors {
+			t.Errorf("%s: expected errors body: %s, got: %s", test.url, test.expectedErrors, body)
+		}
+	}
+}
+
+func TestRespondMarshalError(t *testing.T) {
+	type Plate string
+	type Car struct {
+		VIN   string `jsonapi:"primary,cars"`
+		Plate Plate  `jsonapi:"attribute,plate"`
+	}
+	s := NewSerializer()
+	s.RegisterMarshalerFunc(reflect.TypeOf(Plate("")), func(search map[string]interface{}, memberName string, value reflect.Value) error {
+		if value.String() == "" {
+			return errors.New("plate must be set")
+		}
+		search[memberName] = value.String()
+		return nil
+	})
+	expectedError := "type: cars, member: plate, plate must be set"
+
+	// test errors on the first resource leave the response untouched
+	w := httptest.NewRecorder()
+	cars := []*Car{{VIN: "1"}, {VIN: "2", Plate: "ABC"}}
+	if err := s.Respond(w, nil, http.StatusOK, &cars, nil); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+	if w.Body.Len() > 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("expected nothing written, got headers: %v, body: %s", w.Header(), w.Body.String())
+	}
+
+	// test errors on later resources leave the body truncated
+	w = httptest.NewRecorder()
+	cars = []*Car{{VIN: "1", Plate: "ABC"}, {VIN: "2"}}
+	if err := s.Respond(w, nil, http.StatusCreated, &cars, nil); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code: %d, got: %d", http.StatusCreated, w.Code)
+	}
+}
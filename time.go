@@ -0,0 +1,95 @@
+package jsonapi
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Time formats supported by the format tag option of time.Time and *time.Time members, e.g.:
+// `jsonapi:"attribute,created_at,format=unix"`. Members without a format use TimeFormatRFC3339.
+const (
+	// TimeFormatRFC3339 encodes times as RFC 3339 strings, with sub-second precision if present.
+	TimeFormatRFC3339 = "rfc3339"
+	// TimeFormatUnix encodes times as the number of seconds elapsed since the Unix epoch.
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMilli encodes times as the number of milliseconds elapsed since the Unix epoch.
+	TimeFormatUnixMilli = "unixmilli"
+	// TimeFormatDate encodes times as "YYYY-MM-DD" date strings.
+	TimeFormatDate = "date"
+)
+
+const dateLayout = "2006-01-02"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func timeFormat(options tagOptions) string {
+	if format, ok := options.Get("format"); ok {
+		return format
+	}
+	return TimeFormatRFC3339
+}
+
+func marshalTime(t time.Time, options tagOptions) (interface{}, error) {
+	switch format := timeFormat(options); format {
+	case TimeFormatRFC3339:
+		return t.Format(time.RFC3339Nano), nil
+	case TimeFormatUnix:
+		return t.Unix(), nil
+	case TimeFormatUnixMilli:
+		return t.UnixNano() / int64(time.Millisecond), nil
+	case TimeFormatDate:
+		return t.Format(dateLayout), nil
+	default:
+		return nil, fmt.Errorf("time format: %s, not supported", format)
+	}
+}
+
+// unmarshalTime stores rawValue in field when it's a time.Time or *time.Time. ok is false for any
+// other field type.
+func unmarshalTime(field reflect.Value, rawValue interface{}, options tagOptions) (ok bool, err error) {
+	isPtr := field.Kind() == reflect.Ptr
+	if field.Type() != timeType && !(isPtr && field.Type().Elem() == timeType) {
+		return false, nil
+	}
+
+	// null leaves pointers nil
+	if isPtr && rawValue == nil {
+		return true, nil
+	}
+
+	var t time.Time
+	switch format := timeFormat(options); format {
+	case TimeFormatRFC3339, TimeFormatDate:
+		s, isString := rawValue.(string)
+		if !isString {
+			return true, fmt.Errorf("invalid value for field Time")
+		}
+		layout := time.RFC3339
+		if format == TimeFormatDate {
+			layout = dateLayout
+		}
+		if t, err = time.Parse(layout, s); err != nil {
+			return true, err
+		}
+	case TimeFormatUnix, TimeFormatUnixMilli:
+		n, isNumber := rawValue.(float64)
+		if !isNumber {
+			return true, fmt.Errorf("invalid value for field Time")
+		}
+		if format == TimeFormatUnix {
+			t = time.Unix(int64(n), 0)
+		} else {
+			t = time.Unix(0, int64(n)*int64(time.Millisecond))
+		}
+	default:
+		return true, newTypeError("time format: %s, not supported", format)
+	}
+
+	if isPtr {
+		field.Set(reflect.ValueOf(&t))
+	} else {
+		field.Set(reflect.ValueOf(t))
+	}
+	return true, nil
+}
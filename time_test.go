@@ -0,0 +1,142 @@
+package jsonapi
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type TestTime struct {
+	ID        string     `jsonapi:"primary,test_times"`
+	CreatedAt time.Time  `jsonapi:"attribute,created_at"`
+	UpdatedAt *time.Time `jsonapi:"attribute,updated_at"`
+	DeletedAt *time.Time `jsonapi:"attribute,deleted_at"`
+	Unix      time.Time  `jsonapi:"attribute,unix,format=unix"`
+	UnixMilli time.Time  `jsonapi:"attribute,unix_milli,format=unixmilli"`
+	Birthday  time.Time  `jsonapi:"attribute,birthday,format=date"`
+	SyncedAt  time.Time  `jsonapi:"meta,synced_at,format=unix"`
+}
+
+func TestMarshalTime(t *testing.T) {
+	createdAt := time.Date(2019, time.November, 9, 20, 15, 30, 0, time.UTC)
+	updatedAt := time.Date(2019, time.November, 10, 8, 0, 0, 500000000, time.FixedZone("", -3*60*60))
+	test := TestTime{
+		ID:        "someID",
+		CreatedAt: createdAt,
+		UpdatedAt: &updatedAt,
+		Unix:      createdAt,
+		UnixMilli: updatedAt,
+		Birthday:  time.Date(1934, time.November, 9, 0, 0, 0, 0, time.UTC),
+		SyncedAt:  createdAt,
+	}
+	expected := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_times",
+		"attributes": {
+			"birthday": "1934-11-09",
+			"created_at": "2019-11-09T20:15:30Z",
+			"unix": 1573330530,
+			"unix_milli": 1573383600500,
+			"updated_at": "2019-11-10T08:00:00.5-03:00"
+		},
+		"meta": {
+			"synced_at": 1573330530
+		}
+	},
+	"jsonapi": {
+		"version": "1.0"
+	}
+}`)
+	if got, err := Marshal(&test, &MarshalParams{Format: PrettyFormat}); err != nil {
+		t.Errorf(err.Error())
+	} else {
+		if bytes.Compare(got, expected) != 0 {
+			t.Errorf("Expected:\n%s\nGot:\n%s\n", string(expected), string(got))
+		}
+	}
+
+	// unsupported format
+	type TestUnsupportedFormat struct {
+		ID        string    `jsonapi:"primary,test_times"`
+		CreatedAt time.Time `jsonapi:"attribute,created_at,format=kitchen"`
+	}
+	unsupportedErrMsg := "type: test_times, member: created_at, time format: kitchen, not supported"
+	if _, err := Marshal(&TestUnsupportedFormat{ID: "someID"}, nil); err == nil {
+		t.Errorf("expected error: %s, but got no error", unsupportedErrMsg)
+	} else if err.Error() != unsupportedErrMsg {
+		t.Errorf("expected error: %s, got: %s", unsupportedErrMsg, err.Error())
+	}
+}
+
+func TestUnmarshalTime(t *testing.T) {
+	input := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_times",
+		"attributes": {
+			"birthday": "1934-11-09",
+			"created_at": "2019-11-09T20:15:30Z",
+			"deleted_at": null,
+			"unix": 1573330530,
+			"unix_milli": 1573383600500,
+			"updated_at": "2019-11-10T08:00:00.5-03:00"
+		},
+		"meta": {
+			"synced_at": 1573330530
+		}
+	}
+}`)
+	got := TestTime{}
+	if err := Unmarshal(input, &got); err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Date(2019, time.November, 9, 20, 15, 30, 0, time.UTC)
+	updatedAt := time.Date(2019, time.November, 10, 11, 0, 0, 500000000, time.UTC)
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("expected created at: %s, got: %s", createdAt, got.CreatedAt)
+	}
+	if got.UpdatedAt == nil || !got.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("expected updated at: %s, got: %v", updatedAt, got.UpdatedAt)
+	}
+	if got.DeletedAt != nil {
+		t.Errorf("expected deleted at: %v, got: %s", nil, got.DeletedAt)
+	}
+	if !got.Unix.Equal(createdAt) {
+		t.Errorf("expected unix: %s, got: %s", createdAt, got.Unix)
+	}
+	if !got.UnixMilli.Equal(updatedAt) {
+		t.Errorf("expected unix milli: %s, got: %s", updatedAt, got.UnixMilli)
+	}
+	if birthday := time.Date(1934, time.November, 9, 0, 0, 0, 0, time.UTC); !got.Birthday.Equal(birthday) {
+		t.Errorf("expected birthday: %s, got: %s", birthday, got.Birthday)
+	}
+	if !got.SyncedAt.Equal(createdAt) {
+		t.Errorf("expected synced at: %s, got: %s", createdAt, got.SyncedAt)
+	}
+
+	// wrong types and layouts
+	invalids := map[string]string{
+		`"created_at": 1573330530`:   "type: test_times, pointer: /data/attributes/created_at, invalid value for field Time",
+		`"unix": "1573330530"`:       "type: test_times, pointer: /data/attributes/unix, invalid value for field Time",
+		`"birthday": "09/11/1934"`:   `type: test_times, pointer: /data/attributes/birthday, parsing time "09/11/1934" as "2006-01-02": cannot parse "09/11/1934" as "2006"`,
+		`"created_at": "2019-11-09"`: `type: test_times, pointer: /data/attributes/created_at, parsing time "2019-11-09" as "2006-01-02T15:04:05Z07:00": cannot parse "" as "T"`,
+	}
+	for attribute, expectedErrMsg := range invalids {
+		err := Unmarshal([]byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_times",
+		"attributes": {
+			`+attribute+`
+		}
+	}
+}`), &TestTime{})
+		switch {
+		case err == nil:
+			t.Errorf("expected error: %s, but got no error", expectedErrMsg)
+		case err.Error() != expectedErrMsg:
+			t.Errorf("expected error: %s, got: %s", expectedErrMsg, err.Error())
+		}
+	}
+}
@@ -1,16 +1,64 @@
 package jsonapi
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // Unmarshal parses the JSON:API-encoded data and stores the result in the value pointed to by v.
 func Unmarshal(data []byte, v interface{}) error {
+	return defaultSerializer.Unmarshal(data, v)
+}
+
+// Unmarshal parses the JSON:API-encoded data and stores the result in the value pointed to by v.
+func (s *Serializer) Unmarshal(data []byte, v interface{}) error {
+	return s.UnmarshalWithParams(data, v, nil)
+}
+
+// UnmarshalParams are the optional parameters to control how a document is unmarshaled.
+type UnmarshalParams struct {
+	// Strict makes Unmarshal fail on attributes and relationships not found in v, on values that
+	// don't match the type of their field and on fields of unsupported types.
+	Strict bool
+	// AllErrors makes Unmarshal keep going after member errors and return all of them together as
+	// UnmarshalErrors.
+	AllErrors bool
+	// AllowMissingID leaves the id field zero for primary data without an id, e.g.: in the body of a
+	// request creating a resource whose id is generated by the server. Otherwise missing ids are
+	// stored as empty strings, and fail to unmarshal in integer id fields.
+	AllowMissingID bool
+	// RejectClientIDs makes Unmarshal fail with a 403 Forbidden error when primary data has an id,
+	// for servers that don't support client-generated ids.
+	RejectClientIDs bool
+}
+
+// UnmarshalWithParams parses the JSON:API-encoded data and stores the result in the value pointed
+// to by v, as configured by p.
+func UnmarshalWithParams(data []byte, v interface{}, p *UnmarshalParams) error {
+	return defaultSerializer.UnmarshalWithParams(data, v, p)
+}
+
+// UnmarshalWithParams parses the JSON:API-encoded data and stores the result in the value pointed
+// to by v, as configured by p.
+func (s *Serializer) UnmarshalWithParams(data []byte, v interface{}, p *UnmarshalParams) error {
+	return s.decodeDocument(v, p, func(document interface{}) error {
+		return json.Unmarshal(data, document)
+	})
+}
+
+// decodeDocument stores the top-level document read by decode in the value pointed to by v.
+func (s *Serializer) decodeDocument(v interface{}, p *UnmarshalParams, decode func(interface{}) error) error {
 	rType := reflect.TypeOf(v)
 	rValue := reflect.ValueOf(v)
 	kind := rType.Kind()
@@ -25,6 +73,10 @@ func Unmarshal(data []byte, v interface{}) error {
 		return fmt.Errorf("v must not be nil")
 	}
 
+	if p == nil {
+		p = &UnmarshalParams{}
+	}
+
 	// determine if v is a slice
 	isSlice := false
 	if rType.Elem().Kind() == reflect.Slice {
@@ -34,88 +86,403 @@ func Unmarshal(data []byte, v interface{}) error {
 	// handle compound document
 	if isSlice {
 		document := NewCompoundDocument(nil)
-		if err := json.Unmarshal(data, document); err != nil {
+		if err := decode(document); err != nil {
 			return err
 		}
-		return unmarshalCompoundDocument(v, document)
+		state := newUnmarshalState(s, p, document.Included)
+		if err := state.unmarshalCompoundDocument(v, document); err != nil {
+			return err
+		}
+		return state.err()
 	}
 
 	// handle single document
 	document := NewDocument(nil)
-	if err := json.Unmarshal(data, document); err != nil {
+	if err := decode(document); err != nil {
+		return err
+	}
+	state := newUnmarshalState(s, p, document.Included)
+	if err := state.unmarshalDocument(v, document); err != nil {
 		return err
 	}
-	return unmarshalDocument(v, document)
+	return state.err()
 }
 
 // RegisterUnmarshaler register a new unmarshaler function for type t.
 func RegisterUnmarshaler(t reflect.Type, u unmarshalerFunc) {
-	customUnmarshalers[t] = u
+	defaultSerializer.RegisterUnmarshaler(t, u)
+}
+
+// RegisterUnmarshaler register a new unmarshaler function for type t.
+func (s *Serializer) RegisterUnmarshaler(t reflect.Type, u unmarshalerFunc) {
+	s.RegisterUnmarshalerFunc(t, func(v interface{}, value reflect.Value) error {
+		u(v, value)
+		return nil
+	})
+}
+
+// RegisterUnmarshalerFunc register a new unmarshaler function for type t. Errors returned by u
+// are returned by Unmarshal as *UnmarshalError, annotated with the resource type and member pointer.
+func RegisterUnmarshalerFunc(t reflect.Type, u unmarshalerErrorFunc) {
+	defaultSerializer.RegisterUnmarshalerFunc(t, u)
+}
+
+// RegisterUnmarshalerFunc register a new unmarshaler function for type t. Errors returned by u
+// are returned by Unmarshal as *UnmarshalError, annotated with the resource type and member pointer.
+func (s *Serializer) RegisterUnmarshalerFunc(t reflect.Type, u unmarshalerErrorFunc) {
+	s.codecsMu.Lock()
+	defer s.codecsMu.Unlock()
+	s.customUnmarshalers[t] = u
+}
+
+// UnregisterUnmarshaler removes the unmarshaler function registered for type t, if any.
+func UnregisterUnmarshaler(t reflect.Type) {
+	defaultSerializer.UnregisterUnmarshaler(t)
+}
+
+// UnregisterUnmarshaler removes the unmarshaler function registered for type t, if any.
+func (s *Serializer) UnregisterUnmarshaler(t reflect.Type) {
+	s.codecsMu.Lock()
+	defer s.codecsMu.Unlock()
+	delete(s.customUnmarshalers, t)
+}
+
+func (s *Serializer) customUnmarshaler(t reflect.Type) (unmarshalerErrorFunc, bool) {
+	s.codecsMu.RLock()
+	defer s.codecsMu.RUnlock()
+	cu, ok := s.customUnmarshalers[t]
+	return cu, ok
 }
 
 type unmarshalerFunc = func(interface{}, reflect.Value)
 
-var customUnmarshalers = make(map[reflect.Type]unmarshalerFunc)
+type unmarshalerErrorFunc = func(interface{}, reflect.Value) error
+
+// unmarshalState holds the included resources of the document being unmarshaled.
+type unmarshalState struct {
+	*Serializer
+	params   *UnmarshalParams
+	included map[resourceKey]*Resource
+	// pointers holds the JSON pointer to every resource object and identifier in the document.
+	pointers map[*Resource]string
+	// visited holds the resources being hydrated up the current relationship path, so cyclic
+	// graphs fall back to resource identifiers instead of looping forever.
+	visited map[resourceKey]bool
+	// errs holds the member errors reported so far when collecting all errors.
+	errs UnmarshalErrors
+}
+
+func newUnmarshalState(s *Serializer, p *UnmarshalParams, included []*Resource) *unmarshalState {
+	state := &unmarshalState{
+		Serializer: s,
+		params:     p,
+		included:   newIncludedIndex(included),
+		pointers:   make(map[*Resource]string),
+		visited:    make(map[resourceKey]bool),
+	}
+	for i, resource := range included {
+		state.pointers[resource] = jsonPointer("/included", strconv.Itoa(i))
+	}
+	return state
+}
 
-func unmarshalCompoundDocument(v interface{}, cd *CompoundDocument) error {
+func (s *unmarshalState) unmarshalCompoundDocument(v interface{}, cd *CompoundDocument) error {
 	rValue := reflect.ValueOf(v)
-	for _, resource := range cd.Data {
-		v2 := reflect.New(rValue.Elem().Type().Elem()).Interface()
-		if err := iterateStruct(v2, func(value reflect.Value, memberType memberType, memberNames ...string) error {
-			fieldKind := value.Kind()
-			// TODO this sets ID for all nexted primary tag fields
-			if memberType == memberTypePrimary {
-				switch fieldKind {
-				case reflect.String:
-					value.SetString(resource.ID)
-				case reflect.Int:
-					intID, err := strconv.Atoi(resource.ID)
-					if err != nil {
-						return err
-					}
-					value.SetInt(int64(intID))
-				default:
-					return fmt.Errorf("ID must be a string or int")
-				}
-				return nil
-			}
-			// set raw value
-			return unmarshal(resource, memberType, memberNames, value)
-		}); err != nil {
+	elemType := rValue.Elem().Type().Elem()
+	elemIsPtr := elemType.Kind() == reflect.Ptr
+	if elemIsPtr {
+		elemType = elemType.Elem()
+	}
+	for i, resource := range cd.Data {
+		s.pointers[resource] = jsonPointer("/data", strconv.Itoa(i))
+		if err := s.checkPrimaryID(resource); err != nil {
+			return err
+		}
+		v2 := reflect.New(elemType)
+		if err := s.unmarshalResource(v2.Interface(), resource); err != nil {
 			return err
 		}
+		if !elemIsPtr {
+			v2 = v2.Elem()
+		}
 		value := rValue.Elem()
-		value.Set(reflect.Append(value, reflect.ValueOf(v2).Elem()))
+		value.Set(reflect.Append(value, v2))
+	}
+	return nil
+}
+
+func (s *unmarshalState) unmarshalDocument(v interface{}, d *Document) error {
+	if d.Data == nil {
+		return nil
+	}
+	s.pointers[d.Data] = "/data"
+	if err := s.checkPrimaryID(d.Data); err != nil {
+		return err
+	}
+	return s.unmarshalResource(v, d.Data)
+}
+
+// checkPrimaryID returns an error if the id of primary data resource is client-generated and those
+// are rejected.
+func (s *unmarshalState) checkPrimaryID(resource *Resource) error {
+	if resource.ID != "" && s.params.RejectClientIDs {
+		return &Error{
+			Status: strconv.Itoa(http.StatusForbidden),
+			Title:  "Client-generated ID not allowed",
+			Detail: fmt.Sprintf("resource id '%s' must be generated by the server", resource.ID),
+			Source: map[string]string{
+				"pointer": jsonPointer(s.pointers[resource], "id"),
+			},
+		}
 	}
 	return nil
 }
 
-func unmarshalDocument(v interface{}, d *Document) error {
-	return iterateStruct(v, func(value reflect.Value, memberType memberType, memberNames ...string) error {
-		fieldKind := value.Kind()
-		// TODO this sets ID for all nexted primary tag fields
-		if memberType == memberTypePrimary {
-			switch fieldKind {
-			case reflect.String:
-				value.SetString(d.Data.ID)
-			case reflect.Int:
-				intID, err := strconv.Atoi(d.Data.ID)
-				if err != nil {
-					return err
+// unmarshalResource stores resource in the struct pointed to by v, resolving its relationships
+// from included.
+func (s *unmarshalState) unmarshalResource(v interface{}, resource *Resource) error {
+	if key := resource.key(); !s.visited[key] {
+		s.visited[key] = true
+		defer delete(s.visited, key)
+	}
+
+	// members found in v, to report unknown ones in strict mode
+	attributes := map[string]bool{}
+	relationships := map[string]bool{}
+	typeChecked := false
+
+	if err := s.iterateStruct(v, func(value reflect.Value, memberType memberType, options tagOptions, memberNames ...string) error {
+		switch memberType {
+		case memberTypePrimary:
+			// TODO this sets ID for all nexted primary tag fields
+			// missing ids leave the field zero
+			if resource.ID != "" || !s.params.AllowMissingID {
+				if err := setID(value, resource.ID); err != nil {
+					return s.report(s.newUnmarshalError(resource, memberType, nil, value.Type(), resource.ID, err))
 				}
-				value.SetInt(int64(intID))
-			default:
-				return fmt.Errorf("ID must be a string or int")
+			}
+			// the first primary member declares the resource type, embedded structs may have their own
+			if !typeChecked && resource.Type != memberNames[0] {
+				return s.newTypeConflictError(resource, memberNames[0])
+			}
+			typeChecked = true
+			return nil
+		case memberTypeRelationship:
+			relationships[memberNames[0]] = true
+			if err := s.unmarshalRelationship(resource, memberNames[0], value); err != nil {
+				return s.report(s.newUnmarshalError(resource, memberType, memberNames, nil, resource.Relationships[memberNames[0]], err))
 			}
 			return nil
+		case memberTypeAttribute:
+			attributes[memberNames[0]] = true
 		}
 
 		// set raw value
-		return unmarshal(d.Data, memberType, memberNames, value)
-	})
+		return s.unmarshal(resource, memberType, memberNames, options, value)
+	}); err != nil {
+		return err
+	}
+
+	if !s.params.Strict {
+		return nil
+	}
+	for _, name := range unknownMembers(resource.Attributes, attributes) {
+		if err := s.report(s.newUnmarshalError(resource, memberTypeAttribute, []string{name}, nil, resource.Attributes[name], fmt.Errorf("attribute: %s, not found", name))); err != nil {
+			return err
+		}
+	}
+	for _, name := range unknownMembers(resource.Relationships, relationships) {
+		if err := s.report(s.newUnmarshalError(resource, memberTypeRelationship, []string{name}, nil, resource.Relationships[name], fmt.Errorf("relationship: %s, not found", name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *unmarshalState) unmarshalRelationship(resource *Resource, memberName string, field reflect.Value) error {
+	rawRelationship, found := resource.Relationships[memberName]
+	if !found {
+		return nil
+	}
+	relationship, ok := rawRelationship.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("relationship %s must be an object", memberName)
+	}
+	data, found := relationship["data"]
+	if !found || data == nil {
+		return nil
+	}
+	pointer := jsonPointer(s.pointers[resource], "relationships", memberName, "data")
+
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.Type().Elem().Kind() != reflect.Struct {
+			return newTypeError("relationship must point to a struct")
+		}
+		identifier, err := newResourceIdentifier(data)
+		if err != nil {
+			return err
+		}
+		s.pointers[identifier] = pointer
+		related, err := s.unmarshalRelated(field.Type(), identifier)
+		if err != nil {
+			return err
+		}
+		field.Set(related)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Ptr {
+			return newTypeError("relationship must be pointer or slice of pointers")
+		}
+		if field.Type().Elem().Elem().Kind() != reflect.Struct {
+			return newTypeError("relationship must point to a struct")
+		}
+		identifiers, ok := data.([]interface{})
+		if !ok {
+			return fmt.Errorf("relationship %s data must be an array", memberName)
+		}
+		relateds := reflect.MakeSlice(field.Type(), 0, len(identifiers))
+		for i, rawIdentifier := range identifiers {
+			identifier, err := newResourceIdentifier(rawIdentifier)
+			if err != nil {
+				return err
+			}
+			s.pointers[identifier] = jsonPointer(pointer, strconv.Itoa(i))
+			related, err := s.unmarshalRelated(field.Type().Elem(), identifier)
+			if err != nil {
+				return err
+			}
+			relateds = reflect.Append(relateds, related)
+		}
+		field.Set(relateds)
+	default:
+		return newTypeError("relationship must be pointer or slice of pointers")
+	}
+	return nil
+}
+
+// unmarshalRelated returns a new value of pointer type t hydrated from the included resource
+// matching identifier, or holding only its id when the resource was not included.
+func (s *unmarshalState) unmarshalRelated(t reflect.Type, identifier *Resource) (reflect.Value, error) {
+	related := reflect.New(t.Elem())
+	resource, isIncluded := s.included[identifier.key()]
+	if !isIncluded || s.visited[identifier.key()] {
+		resource = identifier
+	}
+	if err := s.unmarshalResource(related.Interface(), resource); err != nil {
+		return reflect.Value{}, err
+	}
+	return related, nil
+}
+
+// newUnmarshalError returns err as an *UnmarshalError pointing to the member of resource. t is
+// the type rawValue couldn't be stored in, nil when the member itself is invalid. Errors already
+// pointing to a member and error objects, e.g.: type conflicts of related resources, are returned
+// unchanged.
+func (s *unmarshalState) newUnmarshalError(resource *Resource, memberType memberType, memberNames []string, t reflect.Type, rawValue interface{}, err error) error {
+	var unmarshalErr *UnmarshalError
+	var jsonapiErr *Error
+	if errors.As(err, &unmarshalErr) || errors.As(err, &jsonapiErr) {
+		return err
+	}
+	var pointer string
+	switch memberType {
+	case memberTypePrimary:
+		pointer = jsonPointer(s.pointers[resource], "id")
+	case memberTypeAttribute:
+		pointer = jsonPointer(s.pointers[resource], append([]string{"attributes"}, memberNames...)...)
+	case memberTypeMeta:
+		pointer = jsonPointer(s.pointers[resource], append([]string{"meta"}, memberNames...)...)
+	case memberTypeRelationship:
+		pointer = jsonPointer(s.pointers[resource], append([]string{"relationships"}, memberNames...)...)
+	}
+	return &UnmarshalError{
+		ResourceType: resource.Type,
+		Pointer:      pointer,
+		Type:         t,
+		Value:        rawValue,
+		Err:          err,
+	}
+}
+
+// newTypeConflictError returns the 409 Conflict error object of resource not being of type
+// resourceType.
+func (s *unmarshalState) newTypeConflictError(resource *Resource, resourceType string) error {
+	return &Error{
+		Status: strconv.Itoa(http.StatusConflict),
+		Title:  "Resource type conflict",
+		Detail: fmt.Sprintf("resource type '%s' does not match '%s'", resource.Type, resourceType),
+		Source: map[string]string{
+			"pointer": jsonPointer(s.pointers[resource], "type"),
+		},
+	}
+}
+
+// report returns err, or collects it and returns nil when collecting all errors. Only member errors
+// are collected, since any other error leaves nothing sensible to continue with.
+func (s *unmarshalState) report(err error) error {
+	var unmarshalErr *UnmarshalError
+	if !s.params.AllErrors || !errors.As(err, &unmarshalErr) {
+		return err
+	}
+	s.errs = append(s.errs, unmarshalErr)
+	return nil
+}
+
+// err returns the collected member errors, or nil if there are none.
+func (s *unmarshalState) err() error {
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return s.errs
+}
+
+// unknownMembers returns the names in members that aren't in known, in sorted order.
+func unknownMembers(members map[string]interface{}, known map[string]bool) []string {
+	var names []string
+	for name := range members {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonPointer appends tokens to JSON pointer base, escaping them as described in RFC 6901.
+func jsonPointer(base string, tokens ...string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(jsonPointerEscaper.Replace(token))
+	}
+	return b.String()
 }
 
-func unmarshal(resource *Resource, memberType memberType, memberNames []string, field reflect.Value) error {
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func newIncludedIndex(included []*Resource) map[resourceKey]*Resource {
+	index := make(map[resourceKey]*Resource, len(included))
+	for _, resource := range included {
+		index[resource.key()] = resource
+	}
+	return index
+}
+
+func newResourceIdentifier(data interface{}) (*Resource, error) {
+	identifier, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("resource identifier must be an object")
+	}
+	id, _ := identifier["id"].(string)
+	resourceType, _ := identifier["type"].(string)
+	return &Resource{
+		ID:   id,
+		Type: resourceType,
+	}, nil
+}
+
+func (s *unmarshalState) unmarshal(resource *Resource, memberType memberType, memberNames []string, options tagOptions, field reflect.Value) error {
 	// find raw value if exists
 	var search map[string]interface{}
 	switch memberType {
@@ -129,15 +496,55 @@ func unmarshal(resource *Resource, memberType memberType, memberNames []string,
 		return nil
 	}
 
-	if cu, ok := customUnmarshalers[field.Type()]; ok {
-		cu(rawValue, field)
+	return s.unmarshalValue(resource, memberType, memberNames, options, field, rawValue)
+}
+
+// unmarshalValue stores the attribute or meta encoding rawValue in field.
+func (s *unmarshalState) unmarshalValue(resource *Resource, memberType memberType, memberNames []string, options tagOptions, field reflect.Value, rawValue interface{}) error {
+	if err := s.decodeValue(resource, memberType, memberNames, options, field, rawValue); err != nil {
+		return s.report(s.newUnmarshalError(resource, memberType, memberNames, field.Type(), rawValue, err))
+	}
+	return nil
+}
+
+func (s *unmarshalState) decodeValue(resource *Resource, memberType memberType, memberNames []string, options tagOptions, field reflect.Value, rawValue interface{}) error {
+	if cu, ok := s.customUnmarshaler(field.Type()); ok {
+		return cu(rawValue, field)
+	}
+
+	// handle time
+	if ok, err := unmarshalTime(field, rawValue, options); ok {
+		if err != nil {
+			return err
+		}
 		return nil
 	}
 
-	// if pointer, get non-pointer kind
+	// use unmarshaling interfaces if implemented
+	if ok, err := unmarshalInterface(field, rawValue); ok {
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// if pointer, null leaves it nil, otherwise set its element
 	if field.Kind() == reflect.Ptr {
+		if rawValue == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
 		field.Set(reflect.New(field.Type().Elem()))
-		field = field.Elem()
+		return s.decodeValue(resource, memberType, memberNames, options, field.Elem(), rawValue)
+	}
+
+	// like encoding/json, null sets maps, slices and interfaces to nil and leaves other values untouched
+	if rawValue == nil {
+		switch field.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Interface:
+			field.Set(reflect.Zero(field.Type()))
+		}
+		return nil
 	}
 	value := reflect.Indirect(reflect.ValueOf(rawValue))
 
@@ -156,6 +563,11 @@ func unmarshal(resource *Resource, memberType memberType, memberNames []string,
 	case reflect.Int32:
 		fallthrough
 	case reflect.Int64:
+		if s.params.Strict {
+			if err := checkInteger(field, rawValue); err != nil {
+				return err
+			}
+		}
 		return setInt(field, value)
 	case reflect.Uint:
 		fallthrough
@@ -168,22 +580,281 @@ func unmarshal(resource *Resource, memberType memberType, memberNames []string,
 	case reflect.Uint64:
 		fallthrough
 	case reflect.Uintptr:
+		if s.params.Strict {
+			if err := checkInteger(field, rawValue); err != nil {
+				return err
+			}
+		}
 		return setUint(field, value)
 	case reflect.Float32:
 		fallthrough
 	case reflect.Float64:
 		return setFloat(field, value)
-	case reflect.Slice:
-		switch field.Type() {
-		case reflect.TypeOf([]string{}):
-			return setStringSlice(field, value)
-		case reflect.TypeOf([]int{}):
-			return setIntSlice(field, value)
+	case reflect.Slice, reflect.Array:
+		return s.unmarshalSlice(resource, memberType, memberNames, options, field, rawValue)
+	case reflect.Struct:
+		return s.unmarshalStruct(resource, memberType, memberNames, field, rawValue)
+	case reflect.Map:
+		return s.unmarshalMap(resource, memberType, memberNames, options, field, rawValue)
+	case reflect.Interface:
+		// only empty interfaces can hold any decoded value
+		if field.NumMethod() == 0 {
+			field.Set(reflect.ValueOf(rawValue))
+			return nil
+		}
+	}
+	if s.params.Strict {
+		return newTypeError("type: %s, not supported", field.Type())
+	}
+	return nil
+}
+
+// unmarshalSlice stores the JSON array rawValue in slice or array field, decoding each element
+// following the element type. Byte slices are decoded from base64 strings, as in encoding/json.
+func (s *unmarshalState) unmarshalSlice(resource *Resource, memberType memberType, memberNames []string, options tagOptions, field reflect.Value, rawValue interface{}) error {
+	t := field.Type()
+	if text, isString := rawValue.(string); isString && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		b, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return err
+		}
+		field.SetBytes(b)
+		return nil
+	}
+	array, ok := rawValue.([]interface{})
+	if !ok {
+		return fmt.Errorf("invalid value for field %s", t)
+	}
+
+	slice := field
+	if t.Kind() == reflect.Slice {
+		slice = reflect.MakeSlice(t, len(array), len(array))
+	} else {
+		// like encoding/json, extra elements are dropped and missing ones zeroed, unless strict
+		if s.params.Strict && len(array) > field.Len() {
+			return fmt.Errorf("array of %d elements overflows %s", len(array), t)
+		}
+		slice.Set(reflect.Zero(t))
+	}
+	for i := 0; i < len(array) && i < slice.Len(); i++ {
+		path := append(memberNames[:len(memberNames):len(memberNames)], strconv.Itoa(i))
+		if err := checkElemValue(t.Elem(), array[i]); err != nil {
+			if err := s.report(s.newUnmarshalError(resource, memberType, path, t.Elem(), array[i], err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.unmarshalValue(resource, memberType, path, options, slice.Index(i), array[i]); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+// checkInteger returns an error if rawValue is a number that can't be stored in integer field
+// without losing its fraction or overflowing.
+func checkInteger(field reflect.Value, rawValue interface{}) error {
+	f, ok := rawValue.(float64)
+	if !ok {
+		return fmt.Errorf("value is not of type float64")
+	}
+	if f != math.Trunc(f) {
+		return fmt.Errorf("number %v is not an integer", f)
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f >= math.MinInt64 && f < math.MaxInt64 && !field.OverflowInt(int64(f)) {
+			return nil
+		}
+	default:
+		if f >= 0 && f < math.MaxUint64 && !field.OverflowUint(uint64(f)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("number %v overflows %s", f, field.Type())
+}
+
+// checkElemValue returns an error if rawValue can't be stored in a slice element of builtin string
+// or number type t.
+func checkElemValue(t reflect.Type, rawValue interface{}) error {
+	// named types may decode themselves from other values
+	if t.PkgPath() != "" {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if _, ok := rawValue.(string); !ok {
+			return fmt.Errorf("value is not of type string")
+		}
+	case
+		reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		if _, ok := rawValue.(float64); !ok {
+			return fmt.Errorf("value is not of type float64")
 		}
 	}
 	return nil
 }
 
+// unmarshalMap stores the JSON object rawValue in map field. Keys are decoded into strings or
+// encoding.TextUnmarshaler implementations, and values following their element type.
+func (s *unmarshalState) unmarshalMap(resource *Resource, memberType memberType, memberNames []string, options tagOptions, field reflect.Value, rawValue interface{}) error {
+	object, ok := rawValue.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid value for field %s", field.Type())
+	}
+
+	t := field.Type()
+	m := reflect.MakeMapWithSize(t, len(object))
+	for k, rawElem := range object {
+		key := reflect.New(t.Key())
+		if err := unmarshalMapKey(key, k); err != nil {
+			return err
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		path := append(memberNames[:len(memberNames):len(memberNames)], k)
+		if err := s.unmarshalValue(resource, memberType, path, options, elem, rawElem); err != nil {
+			return err
+		}
+		m.SetMapIndex(key.Elem(), elem)
+	}
+	field.Set(m)
+	return nil
+}
+
+// unmarshalMapKey stores JSON object key k in the value key points to, the reverse of
+// marshalMapKey. Integer keys are parsed within the size of their kind.
+func unmarshalMapKey(key reflect.Value, k string) error {
+	if u, isTextUnmarshaler := key.Interface().(encoding.TextUnmarshaler); isTextUnmarshaler {
+		return u.UnmarshalText([]byte(k))
+	}
+	keyValue := key.Elem()
+	switch keyValue.Kind() {
+	case reflect.String:
+		keyValue.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(k, 10, keyValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		keyValue.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(k, 10, keyValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		keyValue.SetUint(u)
+	default:
+		return newTypeError("map key type: %s, not supported", keyValue.Type())
+	}
+	return nil
+}
+
+// unmarshalStruct stores the JSON object rawValue in struct field, matching its keys with the
+// member names of the attribute and meta fields. Structs without tagged fields are decoded
+// following encoding/json.
+func (s *unmarshalState) unmarshalStruct(resource *Resource, parentType memberType, memberNames []string, field reflect.Value, rawValue interface{}) error {
+	object, ok := rawValue.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid value for field %s", field.Type().Name())
+	}
+
+	// iterateStruct and encoding/json need a pointer
+	target := reflect.New(field.Type())
+	target.Elem().Set(field)
+
+	if !s.hasTaggedFields(field.Type()) {
+		b, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		if s.params.Strict {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(target.Interface()); err != nil {
+			return err
+		}
+		field.Set(target.Elem())
+		return nil
+	}
+
+	known := map[string]bool{}
+	if err := s.iterateStruct(target.Interface(), func(value reflect.Value, nestedType memberType, options tagOptions, names ...string) error {
+		// nested structs are plain objects, they have no primary, links or relationship members
+		if nestedType != memberTypeAttribute && nestedType != memberTypeMeta {
+			return nil
+		}
+		name := names[len(names)-1]
+		known[name] = true
+		rawValue, found := object[name]
+		if !found {
+			return nil
+		}
+		path := append(memberNames[:len(memberNames):len(memberNames)], names...)
+		return s.unmarshalValue(resource, parentType, path, options, value, rawValue)
+	}); err != nil {
+		return err
+	}
+	if s.params.Strict {
+		for _, name := range unknownMembers(object, known) {
+			path := append(memberNames[:len(memberNames):len(memberNames)], name)
+			if err := s.report(s.newUnmarshalError(resource, parentType, path, nil, object[name], fmt.Errorf("member: %s, not found", name))); err != nil {
+				return err
+			}
+		}
+	}
+	field.Set(target.Elem())
+	return nil
+}
+
+// unmarshalInterface stores rawValue in field using the AttributeUnmarshaler, json.Unmarshaler or
+// encoding.TextUnmarshaler implementation of its type, in that order. ok is false when the field
+// type doesn't implement any of them.
+func unmarshalInterface(field reflect.Value, rawValue interface{}) (ok bool, err error) {
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem())
+	} else if field.CanAddr() {
+		target = field.Addr()
+	}
+	switch target.Interface().(type) {
+	case AttributeUnmarshaler, json.Unmarshaler, encoding.TextUnmarshaler:
+	default:
+		return false, nil
+	}
+
+	// null leaves pointers nil
+	if field.Kind() == reflect.Ptr && rawValue == nil {
+		return true, nil
+	}
+
+	switch u := target.Interface().(type) {
+	case AttributeUnmarshaler:
+		err = u.UnmarshalAttribute(rawValue)
+	case json.Unmarshaler:
+		b, marshalErr := json.Marshal(rawValue)
+		if marshalErr != nil {
+			return true, marshalErr
+		}
+		err = u.UnmarshalJSON(b)
+	case encoding.TextUnmarshaler:
+		text, isString := rawValue.(string)
+		if !isString {
+			return true, fmt.Errorf("invalid value for field %s", target.Type().Elem().Name())
+		}
+		err = u.UnmarshalText([]byte(text))
+	}
+	if err == nil && field.Kind() == reflect.Ptr {
+		field.Set(target)
+	}
+	return true, err
+}
+
 func deepSearch(tree map[string]interface{}, keys ...string) (interface{}, bool) {
 	key, keys := keys[0], keys[1:]
 	value, ok := tree[key]
@@ -226,36 +897,6 @@ func setFloat(field, value reflect.Value) error {
 	return nil
 }
 
-func setStringSlice(field, value reflect.Value) error {
-	arr := make([]string, value.Len(), value.Cap())
-
-	for i := 0; i < value.Len(); i++ {
-		strVal, ok := value.Index(i).Interface().(string)
-		if !ok {
-			return fmt.Errorf("value is not of type string")
-		}
-		arr[i] = strVal
-	}
-
-	field.Set(reflect.ValueOf(arr))
-	return nil
-}
-
-func setIntSlice(field, value reflect.Value) error {
-	arr := make([]int, value.Len(), value.Cap())
-
-	for i := 0; i < value.Len(); i++ {
-		floatVal, ok := value.Index(i).Interface().(float64)
-		if !ok {
-			return fmt.Errorf("value is not of type float64")
-		}
-		arr[i] = int(floatVal)
-	}
-
-	field.Set(reflect.ValueOf(arr))
-	return nil
-}
-
 func setBool(field, value reflect.Value) error {
 	if _, ok := value.Interface().(bool); !ok {
 		return fmt.Errorf("invalid value for field %s", field.Type().Name())
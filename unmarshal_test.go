@@ -1,6 +1,7 @@
 package jsonapi
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
@@ -38,8 +39,8 @@ type Sample struct {
 	Default         string
 	DefaultWithName string
 
-	// TODO ignored field
-	// IgnoredField string `jsonapi:"-"`
+	// ignored field
+	IgnoredField string `jsonapi:"-"`
 }
 
 type CustomNullableString struct {
@@ -99,11 +100,11 @@ func TestUnmarshalBool(t *testing.T) {
 
 	// test incorrectly sending a string instead of an int
 	wrongTypeOut := TestBool{}
-	wrongTypeErr := "invalid value for field bool"
+	wrongTypeErr := "type: test_bools, pointer: /data/attributes/is_true, invalid value for field bool"
 	wrongType := []byte(`{
 		"data": {
 			"id": "sample-1",
-			"type": "floats",
+			"type": "test_bools",
 			"attributes": {
 				"is_true": "wrong string"
 			}
@@ -313,7 +314,7 @@ func TestUnmarshalInts(t *testing.T) {
 
 	// test incorrectly sending a string instead of an int
 	wrongTypeOut := Sample{}
-	wrongTypeErr := "number has no digits"
+	wrongTypeErr := "type: ints, pointer: /data/attributes/int, number has no digits"
 	wrongType := []byte(`{
 	"data": {
 		"id": "sample-1",
@@ -381,7 +382,7 @@ func TestUnmarshalUints(t *testing.T) {
 
 	// test incorrectly sending a string instead of an uint
 	wrongTypeOut := Sample{}
-	wrongTypeErr := "number has no digits"
+	wrongTypeErr := "type: uints, pointer: /data/attributes/uint, number has no digits"
 	wrongType := []byte(`{
 	"data": {
 		"id": "sample-1",
@@ -402,7 +403,7 @@ func TestUnmarshalUints(t *testing.T) {
 
 func TestUnmarshalFloats(t *testing.T) {
 	type Sample struct {
-		ID      string  `jsonapi:"primary,uints"`
+		ID      string  `jsonapi:"primary,floats"`
 		Float32 float32 `jsonapi:"attribute,float32"`
 		Float64 float64 `jsonapi:"attribute,float64"`
 	}
@@ -434,7 +435,7 @@ func TestUnmarshalFloats(t *testing.T) {
 
 	// test incorrectly sending a string instead of a float
 	wrongTypeOut := Sample{}
-	wrongTypeErr := "number has no digits"
+	wrongTypeErr := "type: floats, pointer: /data/attributes/float32, number has no digits"
 	wrongType := []byte(`{
 	"data": {
 		"id": "sample-1",
@@ -477,6 +478,70 @@ func TestUnmarshalNestedStruct(t *testing.T) {
 	}
 }
 
+func TestUnmarshalNestedStructPtr(t *testing.T) {
+	test := TestLocation{}
+	input := []byte(`{
+		"data": {
+			"id": "someID",
+			"type": "test_locations",
+			"attributes": {
+				"address": {
+					"street": "1 Main St",
+					"city": "Springfield"
+				},
+				"billing": {
+					"street": "2 Side St",
+					"city": "Shelbyville",
+					"country": "US"
+				},
+				"shipping": null,
+				"coordinates": {
+					"lat": 1.5,
+					"lng": -2.5
+				}
+			},
+			"meta": {
+				"source": {
+					"lat": 3
+				}
+			}
+		}
+	}`)
+	if err := Unmarshal(input, &test); err != nil {
+		t.Errorf(err.Error())
+	}
+	if test.Address.Street != "1 Main St" || test.Address.City != "Springfield" {
+		t.Errorf("Address was incorrect, got: %v.", test.Address)
+	}
+	if test.Billing == nil || test.Billing.Country != "US" {
+		t.Errorf("Billing was incorrect, got: %v.", test.Billing)
+	}
+	if test.Shipping != nil {
+		t.Errorf("Shipping was incorrect, got: %v, want: nil.", test.Shipping)
+	}
+	if test.Coordinates.Lat != 1.5 || test.Coordinates.Lng != -2.5 {
+		t.Errorf("Coordinates was incorrect, got: %v.", test.Coordinates)
+	}
+	if test.Source == nil || test.Source.Lat != 3 {
+		t.Errorf("Source was incorrect, got: %v.", test.Source)
+	}
+
+	// test non object value
+	invalid := []byte(`{
+		"data": {
+			"id": "someID",
+			"type": "test_locations",
+			"attributes": {
+				"address": "1 Main St"
+			}
+		}
+	}`)
+	expectedError := "type: test_locations, pointer: /data/attributes/address, invalid value for field TestAddress"
+	if err := Unmarshal(invalid, &TestLocation{}); err == nil || err.Error() != expectedError {
+		t.Errorf("Expected error: %s, got: %v", expectedError, err)
+	}
+}
+
 func TestUnmarshalEmbeddedStruct(t *testing.T) {
 	input := []byte(`{
 		"data": {
@@ -525,7 +590,7 @@ func TestIntSlices(t *testing.T) {
 	}
 
 	wrongStruct := Sample{}
-	wrongError := "value is not of type float64"
+	wrongError := "type: samples, pointer: /data/attributes/slice_ints/0, value is not of type float64"
 	wrongInput := []byte(`{
 		"data": {
 			"id": "someID",
@@ -570,7 +635,7 @@ func TestStringSlices(t *testing.T) {
 	}
 
 	wrongStruct := Sample{}
-	wrongError := "value is not of type string"
+	wrongError := "type: samples, pointer: /data/attributes/slice_strings/0, value is not of type string"
 	wrongInput := []byte(`{
 		"data": {
 			"id": "someID",
@@ -595,6 +660,7 @@ func TestUnmarshalCustomType(t *testing.T) {
 }
 
 func TestUnmarshalCustomTypePtr(t *testing.T) {
+	defer UnregisterUnmarshaler(reflect.TypeOf(&CustomNullableString{}))
 	RegisterUnmarshaler(reflect.TypeOf(&CustomNullableString{}), func(v interface{}, value reflect.Value) {
 		ns := &CustomNullableString{}
 		if v != nil {
@@ -608,7 +674,7 @@ func TestUnmarshalCustomTypePtr(t *testing.T) {
 	inputWithValue := []byte(`{
 	"data": {
 		"id": "someID",
-		"type": "examples",
+		"type": "samples",
 		"attributes": {
 			"custom_struct_ptr": "hello world!"
 		}
@@ -628,7 +694,7 @@ func TestUnmarshalCustomTypePtr(t *testing.T) {
 	inputWithEmptyValue := []byte(`{
 	"data": {
 		"id": "someID",
-		"type": "examples",
+		"type": "samples",
 		"attributes": {
 			"custom_struct_ptr": ""
 		}
@@ -648,7 +714,7 @@ func TestUnmarshalCustomTypePtr(t *testing.T) {
 	inputWithNullValue := []byte(`{
 	"data": {
 		"id": "someID",
-		"type": "examples",
+		"type": "samples",
 		"attributes": {
 			"custom_struct_ptr": null
 		}
@@ -668,7 +734,7 @@ func TestUnmarshalCustomTypePtr(t *testing.T) {
 	inputWithWithoutValue := []byte(`{
 	"data": {
 		"id": "someID",
-		"type": "examples",
+		"type": "samples",
 		"attributes": {
 			"foo": "bar"
 		}
@@ -688,7 +754,7 @@ func TestUnmarshalString(t *testing.T) {
 		ID     string `jsonapi:"primary,strings"`
 		String string `jsonapi:"attribute,string"`
 	}
-	wrongTypeErrMsg := "invalid value for field string"
+	wrongTypeErrMsg := "type: strings, pointer: /data/attributes/string, invalid value for field string"
 	wrongTypeOut := Sample{}
 	wrongType := []byte(`{
 	"data": {
@@ -964,7 +1030,7 @@ func TestUnmarshalErrors(t *testing.T) {
 		}
 	}
 }`)
-	documentNonStringIDErrMsg := `strconv.Atoi: parsing "non-string-id-1": invalid syntax`
+	documentNonStringIDErrMsg := `type: non_string_ids, pointer: /data/id, strconv.ParseInt: parsing "non-string-id-1": invalid syntax`
 	documentNonStringIDErr := Unmarshal(documentNonStringIDIn, &documentNonStringID)
 	switch {
 	case documentNonStringIDErr == nil:
@@ -996,7 +1062,7 @@ func TestUnmarshalErrors(t *testing.T) {
 		}
 	]
 }`)
-	compoundDocumentNonStringIDErrMsg := `strconv.Atoi: parsing "non-string-id-1": invalid syntax`
+	compoundDocumentNonStringIDErrMsg := `type: non_string_ids, pointer: /data/0/id, strconv.ParseInt: parsing "non-string-id-1": invalid syntax`
 	compoundDocumentNonStringIDErr := Unmarshal(compoundDocumentNonStringIDIn, &compoundDocumentNonStringID)
 	switch {
 	case compoundDocumentNonStringIDErr == nil:
@@ -1013,7 +1079,7 @@ func TestUnmarshalErrors(t *testing.T) {
 		ID []byte `jsonapi:"primary,wrong_type_ids"`
 	}
 	wrongTypeID := WrongTypeID{}
-	wrongTypeIDError := "ID must be a string or int"
+	wrongTypeIDError := "pointer: /data/id, ID must be a string or int"
 	wrongTypeIDIn := []byte(`{
 	"data": {
 		"id": "something"
@@ -1039,12 +1105,13 @@ func TestUnmarshalErrors(t *testing.T) {
 			}
 		]
 }`)
+	wrongTypeIDsError := "pointer: /data/0/id, ID must be a string or int"
 	if err := Unmarshal(wrongTypeIDIns, &wrongTypeIDs); err != nil {
-		if err.Error() != wrongTypeIDError {
-			t.Errorf("expected error: %s, got: %s", wrongTypeIDError, err.Error())
+		if err.Error() != wrongTypeIDsError {
+			t.Errorf("expected error: %s, got: %s", wrongTypeIDsError, err.Error())
 		}
 	} else {
-		t.Errorf("expected error: %s, got no error", wrongTypeIDError)
+		t.Errorf("expected error: %s, got no error", wrongTypeIDsError)
 	}
 
 	// TODO make this error out
@@ -1068,3 +1135,905 @@ func TestUnmarshalErrors(t *testing.T) {
 		t.Errorf("unsupported builtin type expected no error, got: %s", unsupportedBuiltinErr.Error())
 	}
 }
+
+func TestUnmarshalRelationships(t *testing.T) {
+	type Author struct {
+		ID   string `jsonapi:"primary,authors"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	type Comment struct {
+		ID     int     `jsonapi:"primary,comments"`
+		Body   string  `jsonapi:"attribute,body"`
+		Author *Author `jsonapi:"relationship,author"`
+	}
+	type Article struct {
+		ID       string     `jsonapi:"primary,articles"`
+		Title    string     `jsonapi:"attribute,title"`
+		Author   *Author    `jsonapi:"relationship,author"`
+		Editor   *Author    `jsonapi:"relationship,editor"`
+		Comments []*Comment `jsonapi:"relationship,comments"`
+	}
+	input := []byte(`{
+	"data": {
+		"id": "article-1",
+		"type": "articles",
+		"attributes": {
+			"title": "Hello world!"
+		},
+		"relationships": {
+			"author": {
+				"data": {
+					"id": "author-1",
+					"type": "authors"
+				}
+			},
+			"editor": {
+				"data": null
+			},
+			"comments": {
+				"data": [
+					{
+						"id": "1",
+						"type": "comments"
+					},
+					{
+						"id": "2",
+						"type": "comments"
+					}
+				]
+			}
+		}
+	},
+	"included": [
+		{
+			"id": "author-1",
+			"type": "authors",
+			"attributes": {
+				"name": "John"
+			}
+		},
+		{
+			"id": "1",
+			"type": "comments",
+			"attributes": {
+				"body": "First!"
+			},
+			"relationships": {
+				"author": {
+					"data": {
+						"id": "author-1",
+						"type": "authors"
+					}
+				}
+			}
+		}
+	]
+}`)
+	article := Article{}
+	if err := Unmarshal(input, &article); err != nil {
+		t.Fatal(err)
+	}
+	if article.Author == nil {
+		t.Fatal("expected author to be set, got nil")
+	}
+	if article.Author.ID != "author-1" || article.Author.Name != "John" {
+		t.Errorf("expected author: %+v, got: %+v", Author{ID: "author-1", Name: "John"}, *article.Author)
+	}
+	if article.Editor != nil {
+		t.Errorf("expected editor to be nil, got: %+v", *article.Editor)
+	}
+	if len(article.Comments) != 2 {
+		t.Fatalf("expected %d comments, got: %d", 2, len(article.Comments))
+	}
+	if article.Comments[0].ID != 1 || article.Comments[0].Body != "First!" {
+		t.Errorf("expected included comment to be hydrated, got: %+v", *article.Comments[0])
+	}
+	if article.Comments[0].Author == nil || article.Comments[0].Author.Name != "John" {
+		t.Errorf("expected included comment author to be hydrated, got: %+v", article.Comments[0].Author)
+	}
+	if article.Comments[1].ID != 2 || article.Comments[1].Body != "" || article.Comments[1].Author != nil {
+		t.Errorf("expected non included comment to only have its id set, got: %+v", *article.Comments[1])
+	}
+
+	// relationships must be pointers or slices of pointers
+	type NonPointerRel struct {
+		ID       string    `jsonapi:"primary,articles"`
+		Comments []Comment `jsonapi:"relationship,comments"`
+	}
+	nonPointerRelErrMsg := "type: articles, pointer: /data/relationships/comments, relationship must be pointer or slice of pointers"
+	nonPointerRelErr := Unmarshal(input, &NonPointerRel{})
+	switch {
+	case nonPointerRelErr == nil:
+		t.Errorf("expected error: %s, but got no error", nonPointerRelErrMsg)
+	case nonPointerRelErr.Error() != nonPointerRelErrMsg:
+		t.Errorf("expected error: %s, got: %s", nonPointerRelErrMsg, nonPointerRelErr.Error())
+	}
+
+	// relationships must point to structs
+	type NonStructRel struct {
+		ID       string    `jsonapi:"primary,articles"`
+		Author   *string   `jsonapi:"relationship,author"`
+		Comments []*string `jsonapi:"relationship,comments"`
+	}
+	nonStructRelIn := []byte(`{
+	"data": {
+		"id": "1",
+		"type": "articles",
+		"relationships": {
+			"author": {"data": {"id": "1", "type": "people"}},
+			"comments": {"data": [{"id": "1", "type": "comments"}]}
+		}
+	}
+}`)
+	nonStructRelErr := UnmarshalWithParams(nonStructRelIn, &NonStructRel{}, &UnmarshalParams{AllErrors: true})
+	expectedErrs := []Error{newInternalServerError(), newInternalServerError()}
+	if errs := NewErrors(nonStructRelErr); !reflect.DeepEqual(errs, expectedErrs) {
+		t.Errorf("expected errors: %+v, got: %+v", expectedErrs, errs)
+	}
+}
+
+type CyclicAuthor struct {
+	ID       string           `jsonapi:"primary,authors"`
+	Name     string           `jsonapi:"attribute,name"`
+	Articles []*CyclicArticle `jsonapi:"relationship,articles"`
+}
+
+type CyclicArticle struct {
+	ID     string        `jsonapi:"primary,articles"`
+	Title  string        `jsonapi:"attribute,title"`
+	Author *CyclicAuthor `jsonapi:"relationship,author"`
+}
+
+func TestUnmarshalCompoundRelationships(t *testing.T) {
+	type Author = CyclicAuthor
+	type Article = CyclicArticle
+	articles := []*Article{
+		{
+			ID:    "article-1",
+			Title: "Hello world 1!",
+			Author: &Author{
+				ID:   "author-1",
+				Name: "John",
+			},
+		},
+		{
+			ID:    "article-2",
+			Title: "Hello world 2!",
+			Author: &Author{
+				ID:   "author-1",
+				Name: "John",
+			},
+		},
+	}
+	b, err := Marshal(&articles, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []*Article{}
+	if err := Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected %d articles, got: %d", 2, len(got))
+	}
+	for i, article := range got {
+		if article.ID != articles[i].ID || article.Title != articles[i].Title {
+			t.Errorf("expected article: %+v, got: %+v", *articles[i], *article)
+		}
+		if article.Author == nil || article.Author.ID != "author-1" || article.Author.Name != "John" {
+			t.Errorf("expected article author to be hydrated, got: %+v", article.Author)
+		}
+	}
+
+	// cyclic relationships must not loop forever
+	cyclic := []byte(`{
+	"data": {
+		"id": "article-1",
+		"type": "articles",
+		"relationships": {
+			"author": {
+				"data": {
+					"id": "author-1",
+					"type": "authors"
+				}
+			}
+		}
+	},
+	"included": [
+		{
+			"id": "author-1",
+			"type": "authors",
+			"attributes": {
+				"name": "John"
+			},
+			"relationships": {
+				"articles": {
+					"data": [
+						{
+							"id": "article-1",
+							"type": "articles"
+						}
+					]
+				}
+			}
+		}
+	]
+}`)
+	article := Article{}
+	if err := Unmarshal(cyclic, &article); err != nil {
+		t.Fatal(err)
+	}
+	if article.Author == nil || len(article.Author.Articles) != 1 {
+		t.Fatalf("expected author with %d article, got: %+v", 1, article.Author)
+	}
+	if article.Author.Articles[0].ID != "article-1" || article.Author.Articles[0].Author != nil {
+		t.Errorf("expected cyclic article to only have its id set, got: %+v", *article.Author.Articles[0])
+	}
+}
+
+func TestUnmarshalIgnoredField(t *testing.T) {
+	input := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "samples",
+		"attributes": {
+			"string": "hello",
+			"-": "world!",
+			"IgnoredField": "world!"
+		}
+	}
+}`)
+	s := Sample{}
+	if err := Unmarshal(input, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.String != "hello" {
+		t.Errorf("String was incorrect, got: %v, want: %v.", s.String, "hello")
+	}
+	if s.IgnoredField != "" {
+		t.Errorf("IgnoredField was incorrect, got: %v, want: %v.", s.IgnoredField, "")
+	}
+}
+
+func TestUnmarshalCustomTypeError(t *testing.T) {
+	type Date struct {
+		Year, Month, Day int
+	}
+	type TestDate struct {
+		ID       string `jsonapi:"primary,test_dates"`
+		Birthday *Date  `jsonapi:"attribute,birthday"`
+	}
+	RegisterUnmarshalerFunc(reflect.TypeOf(&Date{}), func(v interface{}, value reflect.Value) error {
+		s, _ := v.(string)
+		d := Date{}
+		if _, err := fmt.Sscanf(s, "%d-%d-%d", &d.Year, &d.Month, &d.Day); err != nil {
+			return fmt.Errorf("invalid date %q", s)
+		}
+		value.Set(reflect.ValueOf(&d))
+		return nil
+	})
+	defer UnregisterUnmarshaler(reflect.TypeOf(&Date{}))
+
+	valid := TestDate{}
+	if err := Unmarshal([]byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_dates",
+		"attributes": {
+			"birthday": "1934-11-09"
+		}
+	}
+}`), &valid); err != nil {
+		t.Fatal(err)
+	}
+	if valid.Birthday == nil || *valid.Birthday != (Date{Year: 1934, Month: 11, Day: 9}) {
+		t.Errorf("expected birthday: %+v, got: %+v", Date{Year: 1934, Month: 11, Day: 9}, valid.Birthday)
+	}
+
+	invalidErrMsg := `type: test_dates, pointer: /data/attributes/birthday, invalid date "yesterday"`
+	invalidErr := Unmarshal([]byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_dates",
+		"attributes": {
+			"birthday": "yesterday"
+		}
+	}
+}`), &TestDate{})
+	switch {
+	case invalidErr == nil:
+		t.Errorf("expected error: %s, but got no error", invalidErrMsg)
+	case invalidErr.Error() != invalidErrMsg:
+		t.Errorf("expected error: %s, got: %s", invalidErrMsg, invalidErr.Error())
+	}
+}
+
+func TestUnmarshalInterfaces(t *testing.T) {
+	input := []byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_interfaces",
+		"attributes": {
+			"location": [1.5, -2],
+			"price": 10.99,
+			"price_ptr": null,
+			"reference": "deadbeef",
+			"status": "published"
+		},
+		"meta": {
+			"status_ptr": "draft"
+		}
+	}
+}`)
+	got := TestInterfaces{}
+	if err := Unmarshal(input, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Location != (Point{X: 1.5, Y: -2}) {
+		t.Errorf("expected location: %+v, got: %+v", Point{X: 1.5, Y: -2}, got.Location)
+	}
+	if got.Price.value != "10.99" {
+		t.Errorf("expected price: %s, got: %s", "10.99", got.Price.value)
+	}
+	if got.PricePtr != nil {
+		t.Errorf("expected price ptr: %v, got: %+v", nil, *got.PricePtr)
+	}
+	if got.Reference != (UUID{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("expected reference: %x, got: %x", UUID{0xde, 0xad, 0xbe, 0xef}, got.Reference)
+	}
+	if got.Status != StatusPublished {
+		t.Errorf("expected status: %d, got: %d", StatusPublished, got.Status)
+	}
+	if got.StatusPtr == nil || *got.StatusPtr != StatusDraft {
+		t.Errorf("expected status ptr: %d, got: %v", StatusDraft, got.StatusPtr)
+	}
+
+	// errors are annotated with the member
+	invalidErrMsg := `type: test_interfaces, pointer: /data/attributes/status, unknown status "archived"`
+	invalidErr := Unmarshal([]byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_interfaces",
+		"attributes": {
+			"status": "archived"
+		}
+	}
+}`), &TestInterfaces{})
+	switch {
+	case invalidErr == nil:
+		t.Errorf("expected error: %s, but got no error", invalidErrMsg)
+	case invalidErr.Error() != invalidErrMsg:
+		t.Errorf("expected error: %s, got: %s", invalidErrMsg, invalidErr.Error())
+	}
+
+	// text unmarshalers only accept strings
+	wrongTypeErrMsg := "type: test_interfaces, pointer: /data/attributes/reference, invalid value for field UUID"
+	wrongTypeErr := Unmarshal([]byte(`{
+	"data": {
+		"id": "someID",
+		"type": "test_interfaces",
+		"attributes": {
+			"reference": 12
+		}
+	}
+}`), &TestInterfaces{})
+	switch {
+	case wrongTypeErr == nil:
+		t.Errorf("expected error: %s, but got no error", wrongTypeErrMsg)
+	case wrongTypeErr.Error() != wrongTypeErrMsg:
+		t.Errorf("expected error: %s, got: %s", wrongTypeErrMsg, wrongTypeErr.Error())
+	}
+}
+
+func TestUnmarshalMaps(t *testing.T) {
+	test := TestMaps{}
+	input := []byte(`{
+		"data": {
+			"id": "someID",
+			"type": "test_maps",
+			"attributes": {
+				"labels": {
+					"env": "production",
+					"team": "core"
+				},
+				"settings": {
+					"enabled": true,
+					"theme": {
+						"color": "dark"
+					}
+				},
+				"addresses": {
+					"published": {
+						"street": "1 Main St",
+						"city": "Springfield"
+					}
+				},
+				"empty": null
+			},
+			"meta": {
+				"counts": {
+					"views": {
+						"today": 3
+					}
+				}
+			}
+		}
+	}`)
+	if err := Unmarshal(input, &test); err != nil {
+		t.Errorf(err.Error())
+	}
+	expected := TestMaps{
+		ID: "someID",
+		Labels: map[string]string{
+			"env":  "production",
+			"team": "core",
+		},
+		Settings: map[string]interface{}{
+			"enabled": true,
+			"theme": map[string]interface{}{
+				"color": "dark",
+			},
+		},
+		Addresses: map[Status]TestAddress{
+			StatusPublished: {Street: "1 Main St", City: "Springfield"},
+		},
+		Counts: map[string]map[string]int{
+			"views": {"today": 3},
+		},
+	}
+	if !reflect.DeepEqual(test, expected) {
+		t.Errorf("Maps were incorrect, got: %v, want: %v.", test, expected)
+	}
+
+	// test invalid key
+	invalid := []byte(`{
+		"data": {
+			"id": "someID",
+			"type": "test_maps",
+			"attributes": {
+				"addresses": {
+					"archived": {}
+				}
+			}
+		}
+	}`)
+	expectedError := `type: test_maps, pointer: /data/attributes/addresses, unknown status "archived"`
+	if err := Unmarshal(invalid, &TestMaps{}); err == nil || err.Error() != expectedError {
+		t.Errorf("Expected error: %s, got: %v", expectedError, err)
+	}
+}
+
+func TestUnmarshalSlices(t *testing.T) {
+	test := TestSlices{}
+	input := []byte(`{
+		"data": {
+			"id": "someID",
+			"type": "test_slices",
+			"attributes": {
+				"floats": [1.5, 2],
+				"bools": [true, false],
+				"int64s": [9007199254740992],
+				"bindings": ["Hardcover", "Paperback"],
+				"statuses": ["draft", "published"],
+				"pointers": ["hello", null],
+				"addresses": [{"street": "1 Main St", "city": "Springfield"}],
+				"matrix": [[1, 2], [3]],
+				"pair": [4, 5, 6],
+				"bytes": "aGk="
+			}
+		}
+	}`)
+	if err := Unmarshal(input, &test); err != nil {
+		t.Errorf(err.Error())
+	}
+	hello := "hello"
+	expected := TestSlices{
+		ID:        "someID",
+		Floats:    []float64{1.5, 2},
+		Bools:     []bool{true, false},
+		Int64s:    []int64{9007199254740992},
+		Bindings:  []TestBinding{"Hardcover", "Paperback"},
+		Statuses:  []Status{StatusDraft, StatusPublished},
+		Pointers:  []*string{&hello, nil},
+		Addresses: []TestAddress{{Street: "1 Main St", City: "Springfield"}},
+		Matrix:    [][]int{{1, 2}, {3}},
+		Pair:      [2]int{4, 5},
+		Bytes:     []byte("hi"),
+	}
+	if !reflect.DeepEqual(test, expected) {
+		t.Errorf("Slices were incorrect, got: %v, want: %v.", test, expected)
+	}
+
+	// test wrong element types
+	wrongElements := map[string]string{
+		`"bools": [1]`:         "type: test_slices, pointer: /data/attributes/bools/0, invalid value for field bool",
+		`"bindings": [1]`:      "type: test_slices, pointer: /data/attributes/bindings/0, invalid value for field TestBinding",
+		`"matrix": [["a"]]`:    "type: test_slices, pointer: /data/attributes/matrix/0/0, value is not of type float64",
+		`"floats": {"a": 1}`:   "type: test_slices, pointer: /data/attributes/floats, invalid value for field []float64",
+		`"statuses": ["none"]`: `type: test_slices, pointer: /data/attributes/statuses/0, unknown status "none"`,
+	}
+	for attribute, expectedError := range wrongElements {
+		wrongInput := []byte(fmt.Sprintf(`{
+			"data": {
+				"id": "someID",
+				"type": "test_slices",
+				"attributes": {%s}
+			}
+		}`, attribute))
+		if err := Unmarshal(wrongInput, &TestSlices{}); err == nil || err.Error() != expectedError {
+			t.Errorf("Expected error: %s, got: %v", expectedError, err)
+		}
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	type TestStrictAuthor struct {
+		ID   string `jsonapi:"primary,test_strict_authors"`
+		Name string `jsonapi:"attribute,name"`
+	}
+	type TestStrict struct {
+		ID       string            `jsonapi:"primary,test_stricts"`
+		Age      int8              `jsonapi:"attribute,age"`
+		Address  TestAddress       `jsonapi:"attribute,address"`
+		Tags     []string          `jsonapi:"attribute,tags"`
+		Pair     [2]int            `jsonapi:"attribute,pair"`
+		Complex  complex128        `jsonapi:"attribute,complex"`
+		Author   *TestStrictAuthor `jsonapi:"relationship,author"`
+		Internal string            `jsonapi:"-"`
+	}
+	tests := map[string]struct {
+		attributes string
+		pointer    string
+		err        string
+	}{
+		"valid": {
+			attributes: `"age": 12, "address": {"street": "1 Main St"}, "tags": ["a"], "pair": [1]`,
+		},
+		"long array": {
+			attributes: `"pair": [1, 2, 3]`,
+			pointer:    "/data/attributes/pair",
+			err:        "type: test_stricts, pointer: /data/attributes/pair, array of 3 elements overflows [2]int",
+		},
+		"wrong type": {
+			attributes: `"age": "12"`,
+			pointer:    "/data/attributes/age",
+			err:        "type: test_stricts, pointer: /data/attributes/age, value is not of type float64",
+		},
+		"fraction": {
+			attributes: `"age": 12.5`,
+			pointer:    "/data/attributes/age",
+			err:        "type: test_stricts, pointer: /data/attributes/age, number 12.5 is not an integer",
+		},
+		"overflow": {
+			attributes: `"age": 300`,
+			pointer:    "/data/attributes/age",
+			err:        "type: test_stricts, pointer: /data/attributes/age, number 300 overflows int8",
+		},
+		"unknown attribute": {
+			attributes: `"agee": 12`,
+			pointer:    "/data/attributes/agee",
+			err:        "type: test_stricts, pointer: /data/attributes/agee, attribute: agee, not found",
+		},
+		"ignored attribute": {
+			attributes: `"Internal": "secret", "internal": "secret"`,
+			pointer:    "/data/attributes/Internal",
+			err:        "type: test_stricts, pointer: /data/attributes/Internal, attribute: Internal, not found",
+		},
+		"unknown nested member": {
+			attributes: `"address": {"street": "1 Main St", "zip": "12345"}`,
+			pointer:    "/data/attributes/address/zip",
+			err:        "type: test_stricts, pointer: /data/attributes/address/zip, member: zip, not found",
+		},
+		"wrong slice element": {
+			attributes: `"tags": ["a", 1]`,
+			pointer:    "/data/attributes/tags/1",
+			err:        "type: test_stricts, pointer: /data/attributes/tags/1, value is not of type string",
+		},
+		"unsupported kind": {
+			attributes: `"complex": 1`,
+			pointer:    "/data/attributes/complex",
+			err:        "type: test_stricts, pointer: /data/attributes/complex, type: complex128, not supported",
+		},
+	}
+	for name, test := range tests {
+		input := []byte(fmt.Sprintf(`{
+			"data": {
+				"id": "someID",
+				"type": "test_stricts",
+				"attributes": {%s}
+			}
+		}`, test.attributes))
+
+		// non strict mode ignores unknown members, unsupported kinds and extra array elements
+		if test.pointer != "/data/attributes/age" && test.pointer != "/data/attributes/tags/1" {
+			if err := Unmarshal(input, &TestStrict{}); err != nil {
+				t.Errorf("%s: expected no error in non strict mode, got: %v", name, err)
+			}
+		}
+
+		err := UnmarshalWithParams(input, &TestStrict{}, &UnmarshalParams{Strict: true})
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got: %v", name, err)
+			}
+			continue
+		}
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Errorf("%s: expected *UnmarshalError, got: %v", name, err)
+			continue
+		}
+		if unmarshalErr.Pointer != test.pointer {
+			t.Errorf("%s: expected pointer: %s, got: %s", name, test.pointer, unmarshalErr.Pointer)
+		}
+		if err.Error() != test.err {
+			t.Errorf("%s: expected error: %s, got: %s", name, test.err, err.Error())
+		}
+	}
+
+	// test unknown members of relationships and included resources
+	documents := map[string]string{
+		`{
+			"data": {
+				"id": "someID",
+				"type": "test_stricts",
+				"relationships": {
+					"writer": {"data": null}
+				}
+			}
+		}`: "type: test_stricts, pointer: /data/relationships/writer, relationship: writer, not found",
+		`{
+			"data": {
+				"id": "someID",
+				"type": "test_stricts",
+				"relationships": {
+					"author": {"data": {"id": "1", "type": "test_strict_authors"}}
+				}
+			},
+			"included": [
+				{"id": "1", "type": "test_strict_authors", "attributes": {"nickname": "x"}}
+			]
+		}`: "type: test_strict_authors, pointer: /included/0/attributes/nickname, attribute: nickname, not found",
+	}
+	for document, expectedError := range documents {
+		if err := UnmarshalWithParams([]byte(document), &TestStrict{}, &UnmarshalParams{Strict: true}); err == nil || err.Error() != expectedError {
+			t.Errorf("Expected error: %s, got: %v", expectedError, err)
+		}
+	}
+}
+
+func TestUnmarshalAllErrors(t *testing.T) {
+	input := []byte(`{
+		"data": [
+			{
+				"id": "1",
+				"type": "test_slices",
+				"attributes": {
+					"floats": [1, "2"],
+					"bools": "true",
+					"matrix": [[1], ["a", "b"]],
+					"colors": ["red"]
+				}
+			},
+			{
+				"id": "2",
+				"type": "test_slices",
+				"attributes": {
+					"statuses": ["archived"],
+					"addresses": [{"zip": "12345"}]
+				}
+			}
+		]
+	}`)
+	err := UnmarshalWithParams(input, &[]TestSlices{}, &UnmarshalParams{Strict: true, AllErrors: true})
+	var errs UnmarshalErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected UnmarshalErrors, got: %v", err)
+	}
+	expectedPointers := []string{
+		"/data/0/attributes/floats/1",
+		"/data/0/attributes/bools",
+		"/data/0/attributes/matrix/1/0",
+		"/data/0/attributes/matrix/1/1",
+		"/data/0/attributes/colors",
+		"/data/1/attributes/statuses/0",
+		"/data/1/attributes/addresses/0/zip",
+	}
+	pointers := make([]string, len(errs))
+	for i, err := range errs {
+		pointers[i] = err.Pointer
+	}
+	if !reflect.DeepEqual(pointers, expectedPointers) {
+		t.Errorf("expected pointers: %v, got: %v", expectedPointers, pointers)
+	}
+	if jsonapiErrs := NewErrors(err); len(jsonapiErrs) != len(expectedPointers) {
+		t.Errorf("expected %d error objects, got: %d", len(expectedPointers), len(jsonapiErrs))
+	}
+
+	// test only the first error is returned by default
+	err = UnmarshalWithParams(input, &[]TestSlices{}, &UnmarshalParams{Strict: true})
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) || unmarshalErr.Pointer != expectedPointers[0] {
+		t.Errorf("expected error pointing to: %s, got: %v", expectedPointers[0], err)
+	}
+}
+
+func TestUnmarshalTypeConflict(t *testing.T) {
+	documents := map[string]string{
+		`{
+			"data": {
+				"id": "1",
+				"type": "articles"
+			}
+		}`: "/data/type",
+		`{
+			"data": {
+				"id": "1",
+				"type": "authors",
+				"relationships": {
+					"articles": {"data": [{"id": "1", "type": "articles"}, {"id": "2", "type": "comments"}]}
+				}
+			}
+		}`: "/data/relationships/articles/data/1/type",
+		`{
+			"data": {
+				"id": "1",
+				"type": "authors",
+				"relationships": {
+					"articles": {"data": [{"id": "1", "type": "articles"}]}
+				}
+			},
+			"included": [
+				{
+					"id": "1",
+					"type": "articles",
+					"relationships": {
+						"author": {"data": {"id": "2", "type": "users"}}
+					}
+				}
+			]
+		}`: "/included/0/relationships/author/data/type",
+	}
+	for document, expectedPointer := range documents {
+		for _, params := range []*UnmarshalParams{nil, {AllErrors: true}} {
+			err := UnmarshalWithParams([]byte(document), &CyclicAuthor{}, params)
+			conflictErr, ok := err.(*Error)
+			if !ok {
+				t.Errorf("expected *Error, got: %v", err)
+				continue
+			}
+			if conflictErr.Status != "409" {
+				t.Errorf("expected status: %s, got: %s", "409", conflictErr.Status)
+			}
+			if conflictErr.Source["pointer"] != expectedPointer {
+				t.Errorf("expected pointer: %s, got: %s", expectedPointer, conflictErr.Source["pointer"])
+			}
+		}
+	}
+
+	// test compound documents
+	compound := []byte(`{
+		"data": [
+			{"id": "1", "type": "authors"},
+			{"id": "2", "type": "articles"}
+		]
+	}`)
+	expectedErr := Error{
+		Status: "409",
+		Title:  "Resource type conflict",
+		Detail: "resource type 'articles' does not match 'authors'",
+		Source: map[string]string{"pointer": "/data/1/type"},
+	}
+	if errs := NewErrors(Unmarshal(compound, &[]CyclicAuthor{})); !reflect.DeepEqual(errs, []Error{expectedErr}) {
+		t.Errorf("expected errors: %+v, got: %+v", []Error{expectedErr}, errs)
+	}
+}
+
+func TestUnmarshalEmbeddedErrors(t *testing.T) {
+	type Base struct {
+		ID string `jsonapi:"primary,articles"`
+	}
+	type Counts struct {
+		Views int `jsonapi:"attribute,views"`
+	}
+	type Article struct {
+		Base
+		Counts
+		Title string `jsonapi:"attribute,title"`
+	}
+
+	// test type conflicts on embedded primary members
+	conflict := []byte(`{"data": {"id": "1", "type": "people"}}`)
+	expectedConflict := Error{
+		Status: "409",
+		Title:  "Resource type conflict",
+		Detail: "resource type 'people' does not match 'articles'",
+		Source: map[string]string{"pointer": "/data/type"},
+	}
+	article := Article{}
+	if errs := NewErrors(Unmarshal(conflict, &article)); !reflect.DeepEqual(errs, []Error{expectedConflict}) {
+		t.Errorf("expected errors: %+v, got: %+v", []Error{expectedConflict}, errs)
+	}
+
+	// test bad values of embedded members
+	badValue := []byte(`{"data": {"id": "1", "type": "articles", "attributes": {"views": "lots", "title": 1}}}`)
+	expectedError := `type: articles, pointer: /data/attributes/views, number has no digits`
+	if err := Unmarshal(badValue, &Article{}); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+	err := UnmarshalWithParams(badValue, &Article{}, &UnmarshalParams{AllErrors: true})
+	var errs UnmarshalErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected UnmarshalErrors, got: %v", err)
+	}
+	expectedPointers := []string{"/data/attributes/views", "/data/attributes/title"}
+	if len(errs) != len(expectedPointers) {
+		t.Fatalf("expected %d errors, got: %v", len(expectedPointers), errs)
+	}
+	for i, err := range errs {
+		if err.Pointer != expectedPointers[i] {
+			t.Errorf("expected pointer: %s, got: %s", expectedPointers[i], err.Pointer)
+		}
+	}
+}
+
+func TestUnmarshalMissingID(t *testing.T) {
+	type TestMissingID struct {
+		ID    int    `jsonapi:"primary,test_missing_ids"`
+		Title string `jsonapi:"attribute,title"`
+	}
+	withoutID := []byte(`{
+		"data": {
+			"type": "test_missing_ids",
+			"attributes": {
+				"title": "New"
+			}
+		}
+	}`)
+	expectedError := `type: test_missing_ids, pointer: /data/id, strconv.ParseInt: parsing "": invalid syntax`
+	if err := Unmarshal(withoutID, &TestMissingID{}); err == nil || err.Error() != expectedError {
+		t.Errorf("expected error: %s, got: %v", expectedError, err)
+	}
+
+	// test string ids are left empty by default
+	type TestMissingStringID struct {
+		ID    string `jsonapi:"primary,test_missing_ids"`
+		Title string `jsonapi:"attribute,title"`
+	}
+	gotString := TestMissingStringID{ID: "stale"}
+	if err := Unmarshal(withoutID, &gotString); err != nil {
+		t.Errorf(err.Error())
+	}
+	if gotString.ID != "" || gotString.Title != "New" {
+		t.Errorf("expected resource with empty id, got: %+v", gotString)
+	}
+
+	// test allowing missing ids
+	got := TestMissingID{}
+	if err := UnmarshalWithParams(withoutID, &got, &UnmarshalParams{AllowMissingID: true}); err != nil {
+		t.Errorf(err.Error())
+	}
+	if got.ID != 0 || got.Title != "New" {
+		t.Errorf("expected resource without id, got: %+v", got)
+	}
+
+	// test rejecting client-generated ids
+	withID := []byte(`{
+		"data": {
+			"id": "1",
+			"type": "test_missing_ids"
+		}
+	}`)
+	rejectParams := &UnmarshalParams{AllowMissingID: true, RejectClientIDs: true}
+	if err := UnmarshalWithParams(withoutID, &TestMissingID{}, rejectParams); err != nil {
+		t.Errorf(err.Error())
+	}
+	err := UnmarshalWithParams(withID, &TestMissingID{}, rejectParams)
+	var forbiddenErr *Error
+	if !errors.As(err, &forbiddenErr) {
+		t.Fatalf("expected *Error, got: %v", err)
+	}
+	if forbiddenErr.Status != "403" || forbiddenErr.Source["pointer"] != "/data/id" {
+		t.Errorf("expected 403 error pointing to /data/id, got: %+v", forbiddenErr)
+	}
+}